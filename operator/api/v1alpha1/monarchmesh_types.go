@@ -9,6 +9,7 @@
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -25,13 +26,629 @@ type MonarchMeshSpec struct {
 	// +optional
 	Port int32 `json:"port,omitempty"`
 
+	// Spares is the number of extra workers kept warm on the same pod template. Spares are not
+	// published in the mesh Service. When the pod backing a rank fails while the mesh is
+	// Running, a ready spare is promoted into the rank, which is much faster than rescheduling
+	// the failed worker. Status.Ranks reports the pod backing each rank.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Spares int32 `json:"spares,omitempty"`
+
+	// Topology places the workers relative to a node topology such as zones, racks or NVLink
+	// domains. Status.Ranks reports the topology domain of each rank.
+	// +optional
+	Topology *MeshTopology `json:"topology,omitempty"`
+
+	// Networks lists Multus NetworkAttachmentDefinitions attached to every worker as secondary
+	// interfaces, e.g. RDMA or InfiniBand networks. The interface of the first network is passed
+	// to the workers in MONARCH_MESH_INTERFACE to bind the mesh port on it, and the addresses
+	// of each rank on these networks are reported in Status.Ranks.
+	// +optional
+	Networks []MeshNetwork `json:"networks,omitempty"`
+
+	// StartupBarrier delays the start of each worker until the DNS names of all ranks resolve
+	// and their mesh port accepts connections, so that workers don't start before their peers.
+	// The barrier runs as an init container using the operator image. Since workers are not
+	// ready while the barrier runs, the headless Service publishes not-ready addresses. The
+	// progress of the barrier is reported in the StartupBarrier condition while workers wait,
+	// and in the termination message of the init container once it exits.
+	// +optional
+	StartupBarrier *MeshStartupBarrier `json:"startupBarrier,omitempty"`
+
+	// Hostfile maintains a ConfigMap named <mesh>-hostfile listing the ordinal, DNS name, pod IP
+	// and port of every rank, for bootstrap code that expects a static hostfile instead of DNS.
+	// The ConfigMap is updated as workers come and go.
+	// +optional
+	Hostfile *MeshHostfile `json:"hostfile,omitempty"`
+
 	// PodTemplate defines the pod specification for Monarch workers.
 	// Labels and annotations are inherited from the MonarchMesh metadata.
+	// With TemplateRef, it only holds the overrides merged over the template.
 	PodTemplate corev1.PodSpec `json:"podTemplate"`
+
+	// TemplateRef references a MonarchMeshTemplate or ClusterMonarchMeshTemplate providing the
+	// base pod template of the workers. PodTemplate is merged over it with strategic merge patch
+	// semantics, so e.g. a container only needs its name and the fields to override. Changes to
+	// the template reach the workers according to UpdateStrategy, and Status.Template reports
+	// the template generation the workers are created from.
+	// +optional
+	TemplateRef *MeshTemplateReference `json:"templateRef,omitempty"`
+
+	// ClassName selects the MonarchMeshClass whose defaults and limits apply to the mesh. When
+	// empty, the class named by the DefaultClassAnnotation of the namespace applies, if any. The
+	// AllowedClassesAnnotation of the namespace restricts the classes that can be selected.
+	// Status.ClassName reports the class in effect.
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
+	// Priority orders the mesh in the admission queue of the operator. When the mesh at the head
+	// of the queue would exceed a MonarchMeshQuota or the capacity of the cluster, the operator
+	// preempts running meshes of lower priority as a whole: they are queued without workers and
+	// resumed once they fit again. Ignored when the operator admits meshes in FIFO order.
+	// Defaults to the value of the PriorityClass named by PodTemplate.PriorityClassName, or of
+	// the global default PriorityClass. Meshes whose PriorityClass has the Never preemption
+	// policy are queued but never preempt. The MonarchMeshClass of the mesh may cap it.
+	// +kubebuilder:validation:Maximum=1000000000
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// Suspend scales the mesh down to zero workers while keeping the MonarchMesh
+	// and its Service in place. Setting it back to false recreates all workers.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// DisruptionPolicy controls the PodDisruptionBudget created for the mesh workers.
+	// Evicting a single worker terminates the whole Monarch job, so by default voluntary
+	// disruptions (e.g. node drains) are blocked while the mesh is Running.
+	// +kubebuilder:default=BlockWhileRunning
+	// +optional
+	DisruptionPolicy DisruptionPolicy `json:"disruptionPolicy,omitempty"`
+
+	// NetworkIsolation restricts ingress to the mesh workers. When set, the operator creates a
+	// NetworkPolicy that only admits traffic on Port from other workers of the same mesh and
+	// from the selected client pods; all other ingress to the workers is denied.
+	// +optional
+	NetworkIsolation *NetworkIsolation `json:"networkIsolation,omitempty"`
+
+	// TLS enables mutual TLS for mesh communication. When set, every worker gets a certificate
+	// for its stable DNS name (<pod>.<service>.<namespace>.svc) signed by a per-mesh CA, mounted
+	// into all worker containers together with the CA bundle.
+	// +optional
+	TLS *MeshTLS `json:"tls,omitempty"`
+
+	// RBAC configures a per-mesh ServiceAccount for workers and least-privilege discovery
+	// permissions for a client ServiceAccount, as an alternative to binding the cluster-wide
+	// monarch-client-role by hand.
+	// +optional
+	RBAC *MeshRBAC `json:"rbac,omitempty"`
+
+	// Client runs the Monarch client that drives the mesh as part of the MonarchMesh.
+	// The client is started as a Job once all workers are ready. Its failure always fails the
+	// mesh; its completion finishes the mesh unless CompletionPolicy selects another trigger.
+	// +optional
+	Client *MeshClient `json:"client,omitempty"`
+
+	// CompletionPolicy selects what finishes the mesh, turning it into a run-to-completion batch
+	// job. When unset, the mesh finishes with Spec.Client if one is set and runs until deleted
+	// otherwise.
+	// +optional
+	CompletionPolicy CompletionPolicy `json:"completionPolicy,omitempty"`
+
+	// TTLSecondsAfterFinished limits the lifetime of a MonarchMesh that has finished. Once the
+	// mesh has been Succeeded or Failed for this many seconds it is deleted together with all
+	// owned resources. When unset, finished meshes are kept until deleted.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// ActiveDeadlineSeconds is the wall-clock limit for the mesh, counted from Status.StartTime.
+	// Once exceeded, the mesh fails and its workers are torn down. Suspending the mesh resets
+	// the start time.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// IdleTimeout suspends a Running mesh when no client has been active for this long.
+	// A client is active while the Spec.Client Job has running pods, or when it refreshes the
+	// HeartbeatAnnotation. Set Suspend back to false to resume the mesh.
+	// +optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+
+	// VolumeClaimTemplates are claims that every worker gets its own PersistentVolumeClaim for,
+	// e.g. for scratch or checkpoint disks, named <template>-<mesh>-<ordinal>. The PodTemplate
+	// mounts them by template name. Like in a StatefulSet, they cannot be changed once the mesh
+	// has been created. Status.Ranks reports the claims of each rank.
+	// +optional
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+
+	// PersistentVolumeClaimRetentionPolicy selects whether the claims of VolumeClaimTemplates
+	// are retained or deleted when workers are scaled down and when the mesh is deleted.
+	// Claims are retained by default.
+	// +optional
+	PersistentVolumeClaimRetentionPolicy *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
+	// SharedStorage mounts one PersistentVolumeClaim shared by all workers, e.g. for
+	// checkpoints. The claim is independent of the workers, so it survives restarts of the mesh.
+	// +optional
+	SharedStorage *MeshSharedStorage `json:"sharedStorage,omitempty"`
+
+	// UpdateStrategy controls how changes to PodTemplate reach the workers. Monarch workers of
+	// different versions cannot talk to each other, so workers are never updated one by one.
+	// +kubebuilder:default=Recreate
+	// +optional
+	UpdateStrategy MeshUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// MonarchVersion is the Monarch version the client and workers are expected to run. The
+	// controller and worker protocol is not compatible across versions, so workers reporting a
+	// different version through the VersionAnnotation or the operator's version label are
+	// flagged with the VersionSkew condition. With --enforce-image-versions, the validating
+	// webhook also rejects meshes whose Monarch container, the first container of the worker and
+	// client templates, is not tagged with this version.
+	// +optional
+	MonarchVersion string `json:"monarchVersion,omitempty"`
+
+	// StartupTimeout limits how long a worker may take to become ready. Workers stuck past it
+	// are classified by what blocks them, e.g. Unschedulable, ImagePull or CrashLoop, in the
+	// StartupBlocked condition, and StartupTimeoutAction is applied. Workers that were ready
+	// once are no longer starting, and are left alone when they become unready.
+	// +optional
+	StartupTimeout *metav1.Duration `json:"startupTimeout,omitempty"`
+
+	// StartupTimeoutAction selects what happens to workers stuck past StartupTimeout.
+	// +kubebuilder:default=Wait
+	// +optional
+	StartupTimeoutAction StartupTimeoutAction `json:"startupTimeoutAction,omitempty"`
+
+	// UnhealthyNodePolicy selects what happens to ranks whose node is unhealthy, e.g. NotReady,
+	// under disk pressure or tainted by a node problem detector. Affected ranks are always
+	// reported in the NodeUnhealthy condition.
+	// +kubebuilder:default=Report
+	// +optional
+	UnhealthyNodePolicy UnhealthyNodePolicy `json:"unhealthyNodePolicy,omitempty"`
+
+	// Checkpoint signals the workers to checkpoint when they are about to be disrupted, e.g.
+	// preempted by Kueue or the scheduler, on a cordoned node being drained, or on a spot node
+	// being reclaimed. The operator then sets the CheckpointRequested condition and the
+	// CheckpointRequestAnnotation on the mesh and its workers, and holds back voluntary
+	// disruptions through the PodDisruptionBudget until all ranks acknowledged through the
+	// CheckpointAckAnnotation or the window expired. The budget then allows the disruption of
+	// the ranks about to be disrupted, even if Spec.DisruptionPolicy blocks disruptions.
+	// +optional
+	Checkpoint *MeshCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// MeshTemplateReference references the template of a mesh.
+type MeshTemplateReference struct {
+	// Kind is the kind of the template. A MonarchMeshTemplate must be in the namespace of the mesh.
+	// +kubebuilder:validation:Enum=MonarchMeshTemplate;ClusterMonarchMeshTemplate
+	// +kubebuilder:default=MonarchMeshTemplate
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the template.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// Kinds of templates referenced by MeshTemplateReference.
+const (
+	MonarchMeshTemplateKind        = "MonarchMeshTemplate"
+	ClusterMonarchMeshTemplateKind = "ClusterMonarchMeshTemplate"
+)
+
+// StartupTimeoutAction selects what happens to workers stuck past Spec.StartupTimeout.
+// +kubebuilder:validation:Enum=Wait;Recreate;Fail
+type StartupTimeoutAction string
+
+const (
+	// StartupTimeoutWait keeps waiting for the stuck workers, only reporting what blocks them.
+	StartupTimeoutWait StartupTimeoutAction = "Wait"
+
+	// StartupTimeoutRecreate deletes the stuck workers so that they are recreated, e.g. to be
+	// scheduled again or to retry a failing image pull from scratch.
+	StartupTimeoutRecreate StartupTimeoutAction = "Recreate"
+
+	// StartupTimeoutFail fails the mesh.
+	StartupTimeoutFail StartupTimeoutAction = "Fail"
+)
+
+// UnhealthyNodePolicy selects what happens to ranks on unhealthy nodes.
+// +kubebuilder:validation:Enum=Report;Replace
+type UnhealthyNodePolicy string
+
+const (
+	// UnhealthyNodeReport only reports ranks on unhealthy nodes in the NodeUnhealthy condition.
+	UnhealthyNodeReport UnhealthyNodePolicy = "Report"
+
+	// UnhealthyNodeReplace deletes the workers on unhealthy nodes, so that the ranks are
+	// recreated on other nodes or taken over by spares. Workers are deleted gracefully, and
+	// without grace period only once their node is deleted or tainted out-of-service. New
+	// workers are held back by a scheduling gate until the operator gave them a node affinity
+	// avoiding the nodes unhealthy at that time. Nodes are never cordoned.
+	UnhealthyNodeReplace UnhealthyNodePolicy = "Replace"
+)
+
+// MeshCheckpoint configures checkpoint signaling before disruptions.
+type MeshCheckpoint struct {
+	// Window is how long voluntary disruptions are held back for the workers to acknowledge a
+	// checkpoint request. Involuntary disruptions such as a reclaimed spot node cannot be held
+	// back. Defaults to 5 minutes.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+}
+
+// MeshUpdateStrategy controls how changes to the worker pod template are applied.
+// +kubebuilder:validation:Enum=Recreate;OnDelete
+type MeshUpdateStrategy string
+
+const (
+	// MeshUpdateRecreate tears down all workers at once and brings the mesh back on the new
+	// pod template.
+	MeshUpdateRecreate MeshUpdateStrategy = "Recreate"
+
+	// MeshUpdateOnDelete stages pod template changes until the user deletes the workers.
+	// Deleted workers are recreated on the new pod template.
+	MeshUpdateOnDelete MeshUpdateStrategy = "OnDelete"
+)
+
+// CompletionPolicy selects what finishes a MonarchMesh.
+// +kubebuilder:validation:Enum=Client;Workers;Annotation
+type CompletionPolicy string
+
+const (
+	// CompletionPolicyClient finishes the mesh when the Spec.Client Job completes or fails.
+	CompletionPolicyClient CompletionPolicy = "Client"
+
+	// CompletionPolicyWorkers finishes the mesh when every worker container exited. The mesh
+	// succeeds if all of them exited with code 0 and fails as soon as one exits non-zero.
+	CompletionPolicyWorkers CompletionPolicy = "Workers"
+
+	// CompletionPolicyAnnotation finishes the mesh when the CompletionAnnotation is set to
+	// Succeeded or Failed, e.g. by the client or by an external workflow engine.
+	CompletionPolicyAnnotation CompletionPolicy = "Annotation"
+)
+
+// CompletionAnnotation finishes a MonarchMesh with CompletionPolicyAnnotation. Its value is the
+// terminal phase, Succeeded or Failed.
+const CompletionAnnotation = "monarch.pytorch.org/completion"
+
+// HeartbeatAnnotation records the last time a client used the mesh, as an RFC 3339 timestamp.
+// Clients of a mesh with Spec.IdleTimeout refresh it to keep the mesh running.
+const HeartbeatAnnotation = "monarch.pytorch.org/heartbeat"
+
+// VersionAnnotation records the Monarch version a worker pod runs. Workers write it on their
+// own pod so the operator can detect version skew against Spec.MonarchVersion.
+const VersionAnnotation = "monarch.pytorch.org/version"
+
+// StartedAnnotation is set by the operator on a worker pod of a mesh with Spec.StartupTimeout
+// once the worker was ready, as an RFC 3339 timestamp. Started workers that become unready
+// later are not subject to the startup timeout.
+const StartedAnnotation = "monarch.pytorch.org/started"
+
+// CheckpointRequestAnnotation is set on a MonarchMesh with Spec.Checkpoint and on its worker
+// pods when workers are about to be disrupted. Its value identifies the request, and is the time
+// of the request as an RFC 3339 timestamp. Workers can read it from a downwardAPI volume.
+const CheckpointRequestAnnotation = "monarch.pytorch.org/checkpoint-request"
+
+// CheckpointAckAnnotation is set by a worker on its own pod to the value of the
+// CheckpointRequestAnnotation once it has checkpointed.
+const CheckpointAckAnnotation = "monarch.pytorch.org/checkpoint-ack"
+
+// MeshSharedStorage configures the shared storage of a mesh. The claim is mounted at a fixed
+// path in every worker, passed to the workers in MONARCH_SHARED_STORAGE_PATH.
+// +kubebuilder:validation:XValidation:rule="has(self.claimName) != has(self.spec)",message="exactly one of claimName and spec must be set"
+type MeshSharedStorage struct {
+	// ClaimName adopts an existing PersistentVolumeClaim in the namespace of the mesh.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Spec creates a PersistentVolumeClaim named <mesh>-shared. The access modes default to
+	// ReadWriteMany. The spec is only used when creating the claim.
+	// +optional
+	Spec *corev1.PersistentVolumeClaimSpec `json:"spec,omitempty"`
+
+	// RetentionPolicy selects whether the claim is retained or deleted when the MonarchMesh is
+	// deleted. It also applies to adopted claims.
+	// +kubebuilder:default=Retain
+	// +optional
+	RetentionPolicy SharedStorageRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// SharedStorageRetentionPolicy selects what happens to the shared storage when the mesh is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type SharedStorageRetentionPolicy string
+
+const (
+	// SharedStorageRetain keeps the claim when the mesh is deleted.
+	SharedStorageRetain SharedStorageRetentionPolicy = "Retain"
+
+	// SharedStorageDelete deletes the claim together with the mesh.
+	SharedStorageDelete SharedStorageRetentionPolicy = "Delete"
+)
+
+// MeshStartupBarrier configures the startup barrier of the workers.
+type MeshStartupBarrier struct {
+	// Timeout is how long a worker waits for its peers. The init container then fails and is
+	// restarted by the kubelet. Defaults to 10 minutes.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Image overrides the image providing the barrier binary. Defaults to the operator image.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// MeshHostfile configures the hostfile ConfigMap of a mesh.
+type MeshHostfile struct {
+	// MountPath mounts the ConfigMap into all worker and client containers. The hostfile is
+	// available as <mountPath>/hostfile and <mountPath>/hostfile.json. When empty, the
+	// ConfigMap is not mounted and can be referenced from PodTemplate by name.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// MeshNetwork references a Multus NetworkAttachmentDefinition.
+type MeshNetwork struct {
+	// Name is the name of the NetworkAttachmentDefinition.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the NetworkAttachmentDefinition. Defaults to the namespace
+	// of the MonarchMesh.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Interface is the name of the interface in the worker pods. Defaults to the Multus naming
+	// scheme net1, net2, ... in list order.
+	// +optional
+	Interface string `json:"interface,omitempty"`
+}
+
+// MeshTopology configures topology-aware placement of the mesh workers.
+type MeshTopology struct {
+	// Key is the node label defining the topology domains, e.g. topology.kubernetes.io/zone
+	// or a rack label.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Mode selects whether the Packing policy is a scheduling requirement or a preference.
+	// +kubebuilder:default=Preferred
+	// +optional
+	Mode TopologyMode `json:"mode,omitempty"`
+
+	// Packing selects whether workers are packed into as few domains as possible or spread
+	// evenly across domains.
+	// +kubebuilder:default=Pack
+	// +optional
+	Packing TopologyPacking `json:"packing,omitempty"`
+}
+
+// TopologyMode selects how strictly the topology placement is enforced.
+// +kubebuilder:validation:Enum=Required;Preferred
+type TopologyMode string
+
+const (
+	// TopologyRequired leaves workers Pending rather than placing them against the policy.
+	TopologyRequired TopologyMode = "Required"
+
+	// TopologyPreferred lets the scheduler place workers against the policy if it has to.
+	TopologyPreferred TopologyMode = "Preferred"
+)
+
+// TopologyPacking selects how workers are distributed over topology domains.
+// +kubebuilder:validation:Enum=Pack;Spread
+type TopologyPacking string
+
+const (
+	// TopologyPack co-locates all workers in the same topology domain.
+	TopologyPack TopologyPacking = "Pack"
+
+	// TopologySpread spreads workers evenly across topology domains.
+	TopologySpread TopologyPacking = "Spread"
+)
+
+// MeshClient describes the Monarch client run by the operator.
+type MeshClient struct {
+	// Template is the pod specification of the client. The mesh discovery information is injected
+	// into all containers as MONARCH_MESH_* environment variables. RestartPolicy defaults to Never.
+	Template corev1.PodSpec `json:"template"`
+
+	// BackoffLimit is the number of retries before the client Job, and with it the mesh, is
+	// considered failed.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// MeshRBAC configures the identities and permissions created for a MonarchMesh.
+type MeshRBAC struct {
+	// CreateWorkerServiceAccount creates a ServiceAccount named <mesh>-worker and runs the
+	// workers under it, unless PodTemplate.ServiceAccountName is set explicitly.
+	// +optional
+	CreateWorkerServiceAccount bool `json:"createWorkerServiceAccount,omitempty"`
+
+	// ClientServiceAccountName is the ServiceAccount, in the MonarchMesh namespace, of the client
+	// that drives the mesh. It is bound to a Role that only allows reading this MonarchMesh, its
+	// headless Service and its worker pods.
+	// +optional
+	ClientServiceAccountName string `json:"clientServiceAccountName,omitempty"`
 }
 
+// TLSIssuer selects who issues the certificates of a mesh.
+// +kubebuilder:validation:Enum=Operator;CertManager
+type TLSIssuer string
+
+const (
+	// TLSIssuerOperator makes the operator issue and rotate a per-mesh CA and per-pod certificates.
+	TLSIssuerOperator TLSIssuer = "Operator"
+
+	// TLSIssuerCertManager delegates issuing and rotation to cert-manager Certificates.
+	TLSIssuerCertManager TLSIssuer = "CertManager"
+)
+
+// MeshTLS configures mutual TLS for a MonarchMesh.
+type MeshTLS struct {
+	// Issuer selects who issues the certificates. Defaults to CertManager when the operator
+	// runs with cert-manager integration enabled, and to Operator otherwise.
+	// +optional
+	Issuer TLSIssuer `json:"issuer,omitempty"`
+
+	// IssuerRef references the cert-manager Issuer or ClusterIssuer used when Issuer is
+	// CertManager. If unset, the operator creates a per-mesh CA and a CA Issuer backed by it.
+	// +optional
+	IssuerRef *TLSIssuerReference `json:"issuerRef,omitempty"`
+
+	// Duration is the validity period of worker and client certificates. Certificates are
+	// renewed once two thirds of it have elapsed.
+	// +kubebuilder:default="2160h"
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// MountPath is the directory the certificate Secret is mounted at in worker containers.
+	// +kubebuilder:default="/etc/monarch/tls"
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// TLSIssuerReference references a cert-manager issuer.
+type TLSIssuerReference struct {
+	// Name of the issuer.
+	Name string `json:"name"`
+
+	// Kind of the issuer, either Issuer or ClusterIssuer.
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer.
+	// +kubebuilder:default=cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// NetworkIsolation selects the client pods allowed to reach the mesh workers.
+type NetworkIsolation struct {
+	// ClientPodSelector selects the client pods allowed to connect to the mesh port, e.g. by
+	// an application label or a label identifying the client ServiceAccount.
+	// If empty, no client pods are admitted and only mesh-internal traffic is allowed.
+	// +optional
+	ClientPodSelector *metav1.LabelSelector `json:"clientPodSelector,omitempty"`
+
+	// ClientNamespaceSelector selects the namespaces in which ClientPodSelector is evaluated.
+	// If empty, only client pods in the MonarchMesh namespace are admitted.
+	// +optional
+	ClientNamespaceSelector *metav1.LabelSelector `json:"clientNamespaceSelector,omitempty"`
+}
+
+// DisruptionPolicy describes when voluntary disruptions of mesh workers are allowed.
+// +kubebuilder:validation:Enum=BlockWhileRunning;AllowWhenSuspended;None
+type DisruptionPolicy string
+
+const (
+	// DisruptionPolicyBlockWhileRunning blocks voluntary disruptions while the mesh is Running.
+	// Disruptions are allowed while the mesh is still starting up or is suspended.
+	DisruptionPolicyBlockWhileRunning DisruptionPolicy = "BlockWhileRunning"
+
+	// DisruptionPolicyAllowWhenSuspended blocks voluntary disruptions unless the mesh is Suspended.
+	DisruptionPolicyAllowWhenSuspended DisruptionPolicy = "AllowWhenSuspended"
+
+	// DisruptionPolicyNone does not create a PodDisruptionBudget for the mesh.
+	DisruptionPolicyNone DisruptionPolicy = "None"
+)
+
+// MonarchMeshPhase is a simple, high-level summary of where the MonarchMesh is in its lifecycle.
+// +kubebuilder:validation:Enum=Pending;Queued;Running;Suspended;Succeeded;Failed
+type MonarchMeshPhase string
+
+const (
+	// MonarchMeshPending means not all workers are ready yet.
+	MonarchMeshPending MonarchMeshPhase = "Pending"
+
+	// MonarchMeshRunning means all workers are ready.
+	MonarchMeshRunning MonarchMeshPhase = "Running"
+
+	// MonarchMeshSuspended means the mesh has been scaled down via Spec.Suspend.
+	MonarchMeshSuspended MonarchMeshPhase = "Suspended"
+
+	// MonarchMeshQueued means the mesh waits for admission without workers, e.g. because it
+	// would exceed a MonarchMeshQuota. The Queued condition records what holds it back.
+	MonarchMeshQueued MonarchMeshPhase = "Queued"
+
+	// MonarchMeshSucceeded means the mesh finished successfully and its workers were torn down.
+	// This is a terminal phase.
+	MonarchMeshSucceeded MonarchMeshPhase = "Succeeded"
+
+	// MonarchMeshFailed means the mesh finished unsuccessfully and its workers were torn down.
+	// This is a terminal phase.
+	MonarchMeshFailed MonarchMeshPhase = "Failed"
+)
+
+// Condition types reported in MonarchMeshStatus.Conditions.
+const (
+	// MeshConditionReady indicates whether all workers of the mesh are ready.
+	MeshConditionReady = "Ready"
+
+	// MeshConditionDisruptionAllowed reflects whether the mesh PodDisruptionBudget currently
+	// permits voluntary disruptions of workers.
+	MeshConditionDisruptionAllowed = "DisruptionAllowed"
+
+	// MeshConditionFinished indicates that the mesh reached a terminal phase.
+	// The reason records what finished the mesh.
+	MeshConditionFinished = "Finished"
+
+	// MeshConditionIdle indicates whether the mesh was suspended because no client was active
+	// for Spec.IdleTimeout. Only reported when Spec.IdleTimeout is set.
+	MeshConditionIdle = "Idle"
+
+	// MeshConditionVersionSkew indicates whether a worker reports a Monarch version different
+	// from Spec.MonarchVersion. Only reported when Spec.MonarchVersion is set.
+	MeshConditionVersionSkew = "VersionSkew"
+
+	// MeshConditionCertificatesReady indicates whether the TLS certificates of all workers
+	// have been issued. Only reported when Spec.TLS is set.
+	MeshConditionCertificatesReady = "CertificatesReady"
+
+	// MeshConditionStartupBlocked indicates whether workers are stuck starting past
+	// Spec.StartupTimeout. The reason classifies the blocker of the first stuck rank and the
+	// message names all stuck ranks. Only reported when Spec.StartupTimeout is set.
+	MeshConditionStartupBlocked = "StartupBlocked"
+
+	// MeshConditionStartupBarrier indicates whether workers wait in the startup barrier. The
+	// message counts the peers reachable so far and names the others. Only reported when
+	// Spec.StartupBarrier is set.
+	MeshConditionStartupBarrier = "StartupBarrier"
+
+	// MeshConditionNodeUnhealthy indicates whether a rank runs on an unhealthy node. The message
+	// names the affected ranks and nodes.
+	MeshConditionNodeUnhealthy = "NodeUnhealthy"
+
+	// MeshConditionCheckpointRequested indicates whether workers were asked to checkpoint
+	// because they are about to be disrupted. Only reported when Spec.Checkpoint is set.
+	MeshConditionCheckpointRequested = "CheckpointRequested"
+
+	// MeshConditionTemplateResolved indicates whether the template referenced by
+	// Spec.TemplateRef was found. Only reported when Spec.TemplateRef is set.
+	MeshConditionTemplateResolved = "TemplateResolved"
+
+	// MeshConditionClassCompliant indicates whether the mesh complies with the limits of its
+	// MonarchMeshClass. Workers of a mesh that doesn't are left as they are until it complies.
+	// Only reported when the mesh has a class.
+	MeshConditionClassCompliant = "ClassCompliant"
+
+	// MeshConditionQueued indicates whether the mesh waits for admission in the Queued phase.
+	// The reason records what holds it back, e.g. QuotaExceeded, or Preempted when it made room
+	// for a mesh of higher priority. Only reported once the mesh was queued.
+	MeshConditionQueued = "Queued"
+)
+
 // MonarchMeshStatus defines the observed state of MonarchMesh.
 type MonarchMeshStatus struct {
+	// Phase is a high-level summary of the mesh lifecycle.
+	// +optional
+	Phase MonarchMeshPhase `json:"phase,omitempty"`
+
 	// Replicas is the total number of pods targeted by this MonarchMesh.
 	// +optional
 	Replicas int32 `json:"replicas"`
@@ -40,6 +657,51 @@ type MonarchMeshStatus struct {
 	// +optional
 	ReadyReplicas int32 `json:"readyReplicas"`
 
+	// CurrentRevision is the revision of the pod template that all workers run. It advances to
+	// UpdateRevision once every worker has been recreated on it.
+	// +optional
+	CurrentRevision string `json:"currentRevision,omitempty"`
+
+	// UpdateRevision is the revision of the current pod template.
+	// +optional
+	UpdateRevision string `json:"updateRevision,omitempty"`
+
+	// StartTime is the time the mesh was last started or resumed. It is reset while the mesh
+	// is suspended.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the mesh entered the Succeeded or Failed phase.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// SharedStorage reports the claim of Spec.SharedStorage.
+	// +optional
+	SharedStorage *SharedStorageStatus `json:"sharedStorage,omitempty"`
+
+	// Template reports the template of Spec.TemplateRef that was last resolved into the
+	// worker pod template.
+	// +optional
+	Template *TemplateStatus `json:"template,omitempty"`
+
+	// ClassName is the MonarchMeshClass in effect, selected by Spec.ClassName or the default
+	// class of the namespace.
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
+	// QueuePosition is the position of the mesh in the admission queue while it is Queued,
+	// starting at 1 for the next mesh to be admitted. The queue holds the meshes of the namespace
+	// or, when the operator checks the capacity of the cluster, all meshes.
+	// +optional
+	QueuePosition int32 `json:"queuePosition,omitempty"`
+
+	// Ranks maps each logical rank to the worker pod backing it. Ranks are backed by the pod
+	// with the same ordinal until a spare is promoted into them.
+	// +listType=map
+	// +listMapKey=rank
+	// +optional
+	Ranks []RankStatus `json:"ranks,omitempty"`
+
 	// Conditions represent the current state of the MonarchMesh resource.
 	// +listType=map
 	// +listMapKey=type
@@ -47,6 +709,93 @@ type MonarchMeshStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// SharedStorageStatus reports the claim mounted as shared storage.
+type SharedStorageStatus struct {
+	// ClaimName is the name of the PersistentVolumeClaim.
+	ClaimName string `json:"claimName"`
+
+	// Phase is the phase of the claim. It is empty while the claim does not exist.
+	// +optional
+	Phase corev1.PersistentVolumeClaimPhase `json:"phase,omitempty"`
+}
+
+// TemplateStatus reports a resolved mesh template.
+type TemplateStatus struct {
+	// Kind is the kind of the template.
+	Kind string `json:"kind"`
+
+	// Name is the name of the template.
+	Name string `json:"name"`
+
+	// Generation is the generation of the template that was resolved.
+	Generation int64 `json:"generation"`
+}
+
+// RankStatus reports the worker pod backing a logical rank.
+type RankStatus struct {
+	// Rank is the logical rank in the mesh.
+	Rank int32 `json:"rank"`
+
+	// Pod is the name of the worker pod backing the rank.
+	Pod string `json:"pod"`
+
+	// Node is the node the pod is scheduled to.
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// TopologyDomain is the value of the Spec.Topology key on the node of the pod.
+	// +optional
+	TopologyDomain string `json:"topologyDomain,omitempty"`
+
+	// DNSName is the stable DNS name of the pod in the headless Service.
+	// +optional
+	DNSName string `json:"dnsName,omitempty"`
+
+	// IP is the pod IP.
+	// +optional
+	IP string `json:"ip,omitempty"`
+
+	// Networks reports the addresses of the pod on the secondary networks of Spec.Networks,
+	// as published by Multus in the network-status annotation.
+	// +optional
+	Networks []RankNetwork `json:"networks,omitempty"`
+
+	// Volumes reports the claims of the pod created from Spec.VolumeClaimTemplates.
+	// +optional
+	Volumes []RankVolume `json:"volumes,omitempty"`
+}
+
+// RankVolume reports the binding of a per-rank PersistentVolumeClaim.
+type RankVolume struct {
+	// Name is the name of the volume claim template.
+	Name string `json:"name"`
+
+	// ClaimName is the name of the PersistentVolumeClaim.
+	ClaimName string `json:"claimName"`
+
+	// Phase is the phase of the claim. It is empty while the claim does not exist.
+	// +optional
+	Phase corev1.PersistentVolumeClaimPhase `json:"phase,omitempty"`
+
+	// VolumeName is the PersistentVolume the claim is bound to.
+	// +optional
+	VolumeName string `json:"volumeName,omitempty"`
+}
+
+// RankNetwork reports the addresses of a rank on a secondary network.
+type RankNetwork struct {
+	// Name is the namespaced name of the network attachment.
+	Name string `json:"name"`
+
+	// Interface is the name of the interface in the pod.
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// IPs are the addresses of the pod on the network.
+	// +optional
+	IPs []string `json:"ips,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
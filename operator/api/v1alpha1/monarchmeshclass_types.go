@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultClassAnnotation is set on a namespace to select the MonarchMeshClass of the meshes in
+// it that don't set Spec.ClassName. The default is resolved when the mesh is reconciled rather
+// than written to the mesh, so that changing it reaches the existing meshes.
+const DefaultClassAnnotation = "monarch.pytorch.org/default-mesh-class"
+
+// AllowedClassesAnnotation is set on a namespace to the comma-separated MonarchMeshClasses the
+// meshes in it may select through Spec.ClassName, so that users cannot escape the policy of the
+// namespace by selecting another class. The default class of the namespace is always allowed.
+// Without the annotation, any class is allowed.
+const AllowedClassesAnnotation = "monarch.pytorch.org/allowed-mesh-classes"
+
+// MonarchMeshClassSpec defines the defaults and limits enforced on the meshes of a class.
+// Users cannot override them: the admission webhooks apply and validate them, and the
+// reconciler applies them again to the workers and holds back meshes violating them.
+type MonarchMeshClassSpec struct {
+	// PriorityClassName is set on every worker pod.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// SchedulerName is set on every worker pod.
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// Tolerations are added to every worker pod.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// MaxReplicas is the largest Spec.Replicas allowed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// MaxPriority is the largest Spec.Priority allowed, so that meshes cannot preempt meshes of
+	// other namespaces at will. Meshes without Spec.Priority take the priority of their
+	// PriorityClass, which PriorityClassName controls.
+	// +kubebuilder:validation:Maximum=1000000000
+	// +optional
+	MaxPriority *int32 `json:"maxPriority,omitempty"`
+
+	// AllowedImages lists the images the worker and client containers and the startup barrier
+	// may run. An entry ending in "*" allows every image starting with the rest of the entry,
+	// e.g. "ghcr.io/meta-pytorch/*"; other entries must match the image exactly. All images are
+	// allowed when empty.
+	// +optional
+	AllowedImages []string `json:"allowedImages,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=monarchmeshclasses,scope=Cluster
+
+// MonarchMeshClass is the Schema for the monarchmeshclasses API. Meshes select a class through
+// Spec.ClassName or the DefaultClassAnnotation of their namespace.
+type MonarchMeshClass struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the defaults and limits of the class
+	// +required
+	Spec MonarchMeshClassSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// MonarchMeshClassList contains a list of MonarchMeshClass
+type MonarchMeshClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []MonarchMeshClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MonarchMeshClass{}, &MonarchMeshClassList{})
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MonarchMeshQuotaSpec defines the limits on the meshes of a namespace.
+//
+// Meshes count against the quotas of their namespace unless they are suspended, finished or
+// queued. Meshes are checked when they are created or resumed; changes to a running mesh are
+// only checked by the validating webhook, and never take workers away from it.
+type MonarchMeshQuotaSpec struct {
+	// Meshes is the maximum number of meshes.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Meshes *int32 `json:"meshes,omitempty"`
+
+	// Replicas is the maximum total number of workers, counting Spec.Replicas and Spec.Spares of
+	// every mesh.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources limits the total requests of the workers per resource, e.g. nvidia.com/gpu.
+	// The requests of a worker are taken from the PodTemplate of its mesh, so requests only set
+	// in the template of Spec.TemplateRef are not counted.
+	// +optional
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+
+	// OverQuotaAction selects what happens to meshes that would exceed the quota.
+	// +kubebuilder:default=Reject
+	// +optional
+	OverQuotaAction OverQuotaAction `json:"overQuotaAction,omitempty"`
+}
+
+// OverQuotaAction selects what happens to meshes that would exceed a MonarchMeshQuota.
+// +kubebuilder:validation:Enum=Reject;Queue
+type OverQuotaAction string
+
+const (
+	// OverQuotaReject rejects the mesh at admission, whatever its priority. Meshes admitted
+	// while the webhooks are disabled are queued by the reconciler.
+	OverQuotaReject OverQuotaAction = "Reject"
+
+	// OverQuotaQueue admits the mesh, which then waits in the Queued phase without workers until
+	// enough quota is released, or meshes of lower priority are preempted to make room.
+	OverQuotaQueue OverQuotaAction = "Queue"
+)
+
+// MonarchMeshQuotaStatus defines the observed usage of a MonarchMeshQuota.
+type MonarchMeshQuotaStatus struct {
+	// Used is the usage of the meshes counting against the quota.
+	// +optional
+	Used QuotaUsage `json:"used,omitzero"`
+
+	// Queued is the number of meshes of the namespace waiting in the Queued phase.
+	// +optional
+	Queued int32 `json:"queued,omitempty"`
+}
+
+// QuotaUsage measures meshes against a MonarchMeshQuota.
+type QuotaUsage struct {
+	// Meshes is the number of meshes.
+	// +optional
+	Meshes int32 `json:"meshes"`
+
+	// Replicas is the number of workers, spares included.
+	// +optional
+	Replicas int32 `json:"replicas"`
+
+	// Resources is the total requests of the workers for the resources limited by the quota.
+	// +optional
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=monarchmeshquotas,scope=Namespaced
+
+// MonarchMeshQuota is the Schema for the monarchmeshquotas API. It limits the number of meshes,
+// workers and accelerators of the meshes in its namespace.
+type MonarchMeshQuota struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the limits of the quota
+	// +required
+	Spec MonarchMeshQuotaSpec `json:"spec"`
+
+	// status defines the observed usage of the quota
+	// +optional
+	Status MonarchMeshQuotaStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// MonarchMeshQuotaList contains a list of MonarchMeshQuota
+type MonarchMeshQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []MonarchMeshQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MonarchMeshQuota{}, &MonarchMeshQuotaList{})
+}
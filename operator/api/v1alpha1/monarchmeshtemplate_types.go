@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MonarchMeshTemplateSpec defines the worker pod template shared by the meshes referencing it.
+type MonarchMeshTemplateSpec struct {
+	// PodTemplate is the base pod specification for the workers of the referencing meshes.
+	// The PodTemplate of each mesh is merged over it with strategic merge patch semantics,
+	// so containers, volumes and env vars are overridden by name.
+	PodTemplate corev1.PodSpec `json:"podTemplate"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=monarchmeshtemplates,scope=Namespaced
+
+// MonarchMeshTemplate is the Schema for the monarchmeshtemplates API. It can be referenced by
+// MonarchMeshes in the same namespace.
+type MonarchMeshTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the shared worker pod template
+	// +required
+	Spec MonarchMeshTemplateSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// MonarchMeshTemplateList contains a list of MonarchMeshTemplate
+type MonarchMeshTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []MonarchMeshTemplate `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clustermonarchmeshtemplates,scope=Cluster
+
+// ClusterMonarchMeshTemplate is the Schema for the clustermonarchmeshtemplates API. It can be
+// referenced by MonarchMeshes in any namespace.
+type ClusterMonarchMeshTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the shared worker pod template
+	// +required
+	Spec MonarchMeshTemplateSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterMonarchMeshTemplateList contains a list of ClusterMonarchMeshTemplate
+type ClusterMonarchMeshTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ClusterMonarchMeshTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&MonarchMeshTemplate{}, &MonarchMeshTemplateList{},
+		&ClusterMonarchMeshTemplate{}, &ClusterMonarchMeshTemplateList{},
+	)
+}
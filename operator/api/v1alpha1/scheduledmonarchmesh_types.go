@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScheduledMonarchMeshSpec defines when meshes are created and what they look like.
+type ScheduledMonarchMeshSpec struct {
+	// Schedule is the cron schedule of the meshes, in the format of Kubernetes CronJobs, e.g.
+	// "0 2 * * *" for every night at 2am or "@weekly".
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the IANA time zone the Schedule is interpreted in, e.g. "Europe/London".
+	// Defaults to the time zone of the operator, usually UTC.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// StartingDeadlineSeconds is how late a mesh may still be created after its scheduled time,
+	// e.g. while the operator was down. Runs missed by more are skipped. Defaults to no deadline.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// ConcurrencyPolicy selects what happens when a run is due while a mesh of a previous run is
+	// still active.
+	// +kubebuilder:default=Allow
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend stops the creation of new meshes. Active meshes are left running, and runs missed
+	// while suspended are subject to StartingDeadlineSeconds once resumed.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// MeshTemplate is the template of the meshes created on schedule. The meshes are named
+	// <name>-<scheduled time in minutes since the epoch>, at most 52 characters, carry the labels
+	// and annotations of the template and the ScheduledAtAnnotation, and are owned by the
+	// ScheduledMonarchMesh.
+	// +required
+	MeshTemplate ScheduledMeshTemplate `json:"meshTemplate"`
+
+	// SuccessfulMeshesHistoryLimit is the number of Succeeded meshes to keep. Older ones are
+	// deleted.
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SuccessfulMeshesHistoryLimit *int32 `json:"successfulMeshesHistoryLimit,omitempty"`
+
+	// FailedMeshesHistoryLimit is the number of Failed meshes to keep. Older ones are deleted.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailedMeshesHistoryLimit *int32 `json:"failedMeshesHistoryLimit,omitempty"`
+}
+
+// ScheduledMeshTemplate describes the MonarchMeshes created by a ScheduledMonarchMesh.
+type ScheduledMeshTemplate struct {
+	// metadata holds the labels and annotations of the meshes.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec is the spec of the meshes.
+	// +required
+	Spec MonarchMeshSpec `json:"spec"`
+}
+
+// ConcurrencyPolicy selects how a ScheduledMonarchMesh treats the active meshes of previous runs.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyAllow creates the mesh of the run alongside the active meshes.
+	ConcurrencyAllow ConcurrencyPolicy = "Allow"
+
+	// ConcurrencyForbid skips the run while a mesh is active.
+	ConcurrencyForbid ConcurrencyPolicy = "Forbid"
+
+	// ConcurrencyReplace deletes the active meshes before creating the mesh of the run.
+	ConcurrencyReplace ConcurrencyPolicy = "Replace"
+)
+
+// ScheduledAtAnnotation records the scheduled time of a mesh created by a ScheduledMonarchMesh,
+// as an RFC 3339 timestamp.
+const ScheduledAtAnnotation = "monarch.pytorch.org/scheduled-at"
+
+// ScheduledMonarchMeshStatus defines the observed state of a ScheduledMonarchMesh.
+type ScheduledMonarchMeshStatus struct {
+	// Active lists the names of the meshes created by the schedule that are not finished yet.
+	// +listType=set
+	// +optional
+	Active []string `json:"active,omitempty"`
+
+	// LastScheduleTime is the scheduled time of the last mesh created.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the completion time of the last mesh that Succeeded.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=scheduledmonarchmeshes,scope=Namespaced
+// +kubebuilder:validation:XValidation:rule="size(self.metadata.name) <= 43",message="name must be no more than 43 characters, so that the names of its meshes fit in 52 characters"
+
+// ScheduledMonarchMesh is the Schema for the scheduledmonarchmeshes API. It creates
+// MonarchMeshes from a template on a cron schedule, e.g. for nightly evaluations. Its name is
+// limited to 43 characters, like the name of a CronJob is limited to 52, so that the names of
+// its meshes leave room for the revision hash the StatefulSet of the workers appends to label
+// values limited to 63 characters.
+type ScheduledMonarchMesh struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the schedule and the template of the meshes
+	// +required
+	Spec ScheduledMonarchMeshSpec `json:"spec"`
+
+	// status defines the observed state of the schedule
+	// +optional
+	Status ScheduledMonarchMeshStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScheduledMonarchMeshList contains a list of ScheduledMonarchMesh
+type ScheduledMonarchMeshList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ScheduledMonarchMesh `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScheduledMonarchMesh{}, &ScheduledMonarchMeshList{})
+}
@@ -1,20 +1,287 @@
+//go:build !ignore_autogenerated
+
 /*
- * Copyright (c) Meta Platforms, Inc. and affiliates.
- * All rights reserved.
- *
- * This source code is licensed under the BSD-style license found in the
- * LICENSE file in the root directory of this source tree.
- */
+BSD 3-Clause License
+
+Copyright (c) Meta Platforms, Inc. and affiliates.
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+  list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+  this list of conditions and the following disclaimer in the documentation
+  and/or other materials provided with the distribution.
+
+* Neither the name of the copyright holder nor the names of its
+  contributors may be used to endorse or promote products derived from
+  this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
 
 // Code generated by controller-gen. DO NOT EDIT.
 
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterMonarchMeshTemplate) DeepCopyInto(out *ClusterMonarchMeshTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMonarchMeshTemplate.
+func (in *ClusterMonarchMeshTemplate) DeepCopy() *ClusterMonarchMeshTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMonarchMeshTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterMonarchMeshTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterMonarchMeshTemplateList) DeepCopyInto(out *ClusterMonarchMeshTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterMonarchMeshTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMonarchMeshTemplateList.
+func (in *ClusterMonarchMeshTemplateList) DeepCopy() *ClusterMonarchMeshTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMonarchMeshTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterMonarchMeshTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshCheckpoint) DeepCopyInto(out *MeshCheckpoint) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshCheckpoint.
+func (in *MeshCheckpoint) DeepCopy() *MeshCheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshCheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshClient) DeepCopyInto(out *MeshClient) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshClient.
+func (in *MeshClient) DeepCopy() *MeshClient {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshClient)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHostfile) DeepCopyInto(out *MeshHostfile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHostfile.
+func (in *MeshHostfile) DeepCopy() *MeshHostfile {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHostfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshNetwork) DeepCopyInto(out *MeshNetwork) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshNetwork.
+func (in *MeshNetwork) DeepCopy() *MeshNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshRBAC) DeepCopyInto(out *MeshRBAC) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshRBAC.
+func (in *MeshRBAC) DeepCopy() *MeshRBAC {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshRBAC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshSharedStorage) DeepCopyInto(out *MeshSharedStorage) {
+	*out = *in
+	if in.Spec != nil {
+		in, out := &in.Spec, &out.Spec
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshSharedStorage.
+func (in *MeshSharedStorage) DeepCopy() *MeshSharedStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshSharedStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshStartupBarrier) DeepCopyInto(out *MeshStartupBarrier) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshStartupBarrier.
+func (in *MeshStartupBarrier) DeepCopy() *MeshStartupBarrier {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshStartupBarrier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshTLS) DeepCopyInto(out *MeshTLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(TLSIssuerReference)
+		**out = **in
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshTLS.
+func (in *MeshTLS) DeepCopy() *MeshTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshTemplateReference) DeepCopyInto(out *MeshTemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshTemplateReference.
+func (in *MeshTemplateReference) DeepCopy() *MeshTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshTopology) DeepCopyInto(out *MeshTopology) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshTopology.
+func (in *MeshTopology) DeepCopy() *MeshTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonarchMesh) DeepCopyInto(out *MonarchMesh) {
 	*out = *in
@@ -43,31 +310,57 @@ func (in *MonarchMesh) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonarchMeshList) DeepCopyInto(out *MonarchMeshList) {
+func (in *MonarchMeshClass) DeepCopyInto(out *MonarchMeshClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshClass.
+func (in *MonarchMeshClass) DeepCopy() *MonarchMeshClass {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonarchMeshClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshClassList) DeepCopyInto(out *MonarchMeshClassList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MonarchMesh, len(*in))
+		*out = make([]MonarchMeshClass, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshList.
-func (in *MonarchMeshList) DeepCopy() *MonarchMeshList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshClassList.
+func (in *MonarchMeshClassList) DeepCopy() *MonarchMeshClassList {
 	if in == nil {
 		return nil
 	}
-	out := new(MonarchMeshList)
+	out := new(MonarchMeshClassList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MonarchMeshList) DeepCopyObject() runtime.Object {
+func (in *MonarchMeshClassList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -75,39 +368,700 @@ func (in *MonarchMeshList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonarchMeshSpec) DeepCopyInto(out *MonarchMeshSpec) {
+func (in *MonarchMeshClassSpec) DeepCopyInto(out *MonarchMeshClassSpec) {
 	*out = *in
-	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPriority != nil {
+		in, out := &in.MaxPriority, &out.MaxPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AllowedImages != nil {
+		in, out := &in.AllowedImages, &out.AllowedImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshSpec.
-func (in *MonarchMeshSpec) DeepCopy() *MonarchMeshSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshClassSpec.
+func (in *MonarchMeshClassSpec) DeepCopy() *MonarchMeshClassSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MonarchMeshSpec)
+	out := new(MonarchMeshClassSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonarchMeshStatus) DeepCopyInto(out *MonarchMeshStatus) {
+func (in *MonarchMeshList) DeepCopyInto(out *MonarchMeshList) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MonarchMesh, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshStatus.
-func (in *MonarchMeshStatus) DeepCopy() *MonarchMeshStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshList.
+func (in *MonarchMeshList) DeepCopy() *MonarchMeshList {
 	if in == nil {
 		return nil
 	}
-	out := new(MonarchMeshStatus)
+	out := new(MonarchMeshList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonarchMeshList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshQuota) DeepCopyInto(out *MonarchMeshQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshQuota.
+func (in *MonarchMeshQuota) DeepCopy() *MonarchMeshQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonarchMeshQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshQuotaList) DeepCopyInto(out *MonarchMeshQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MonarchMeshQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshQuotaList.
+func (in *MonarchMeshQuotaList) DeepCopy() *MonarchMeshQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonarchMeshQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshQuotaSpec) DeepCopyInto(out *MonarchMeshQuotaSpec) {
+	*out = *in
+	if in.Meshes != nil {
+		in, out := &in.Meshes, &out.Meshes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshQuotaSpec.
+func (in *MonarchMeshQuotaSpec) DeepCopy() *MonarchMeshQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshQuotaStatus) DeepCopyInto(out *MonarchMeshQuotaStatus) {
+	*out = *in
+	in.Used.DeepCopyInto(&out.Used)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshQuotaStatus.
+func (in *MonarchMeshQuotaStatus) DeepCopy() *MonarchMeshQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshSpec) DeepCopyInto(out *MonarchMeshSpec) {
+	*out = *in
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(MeshTopology)
+		**out = **in
+	}
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]MeshNetwork, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartupBarrier != nil {
+		in, out := &in.StartupBarrier, &out.StartupBarrier
+		*out = new(MeshStartupBarrier)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hostfile != nil {
+		in, out := &in.Hostfile, &out.Hostfile
+		*out = new(MeshHostfile)
+		**out = **in
+	}
+	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(MeshTemplateReference)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NetworkIsolation != nil {
+		in, out := &in.NetworkIsolation, &out.NetworkIsolation
+		*out = new(NetworkIsolation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(MeshTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RBAC != nil {
+		in, out := &in.RBAC, &out.RBAC
+		*out = new(MeshRBAC)
+		**out = **in
+	}
+	if in.Client != nil {
+		in, out := &in.Client, &out.Client
+		*out = new(MeshClient)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]corev1.PersistentVolumeClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PersistentVolumeClaimRetentionPolicy != nil {
+		in, out := &in.PersistentVolumeClaimRetentionPolicy, &out.PersistentVolumeClaimRetentionPolicy
+		*out = new(appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy)
+		**out = **in
+	}
+	if in.SharedStorage != nil {
+		in, out := &in.SharedStorage, &out.SharedStorage
+		*out = new(MeshSharedStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupTimeout != nil {
+		in, out := &in.StartupTimeout, &out.StartupTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		*out = new(MeshCheckpoint)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshSpec.
+func (in *MonarchMeshSpec) DeepCopy() *MonarchMeshSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshStatus) DeepCopyInto(out *MonarchMeshStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SharedStorage != nil {
+		in, out := &in.SharedStorage, &out.SharedStorage
+		*out = new(SharedStorageStatus)
+		**out = **in
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(TemplateStatus)
+		**out = **in
+	}
+	if in.Ranks != nil {
+		in, out := &in.Ranks, &out.Ranks
+		*out = make([]RankStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshStatus.
+func (in *MonarchMeshStatus) DeepCopy() *MonarchMeshStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshTemplate) DeepCopyInto(out *MonarchMeshTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshTemplate.
+func (in *MonarchMeshTemplate) DeepCopy() *MonarchMeshTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonarchMeshTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshTemplateList) DeepCopyInto(out *MonarchMeshTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MonarchMeshTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshTemplateList.
+func (in *MonarchMeshTemplateList) DeepCopy() *MonarchMeshTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonarchMeshTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonarchMeshTemplateSpec) DeepCopyInto(out *MonarchMeshTemplateSpec) {
+	*out = *in
+	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonarchMeshTemplateSpec.
+func (in *MonarchMeshTemplateSpec) DeepCopy() *MonarchMeshTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonarchMeshTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkIsolation) DeepCopyInto(out *NetworkIsolation) {
+	*out = *in
+	if in.ClientPodSelector != nil {
+		in, out := &in.ClientPodSelector, &out.ClientPodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientNamespaceSelector != nil {
+		in, out := &in.ClientNamespaceSelector, &out.ClientNamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkIsolation.
+func (in *NetworkIsolation) DeepCopy() *NetworkIsolation {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIsolation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaUsage) DeepCopyInto(out *QuotaUsage) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaUsage.
+func (in *QuotaUsage) DeepCopy() *QuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RankNetwork) DeepCopyInto(out *RankNetwork) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RankNetwork.
+func (in *RankNetwork) DeepCopy() *RankNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(RankNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RankStatus) DeepCopyInto(out *RankStatus) {
+	*out = *in
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]RankNetwork, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]RankVolume, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RankStatus.
+func (in *RankStatus) DeepCopy() *RankStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RankStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RankVolume) DeepCopyInto(out *RankVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RankVolume.
+func (in *RankVolume) DeepCopy() *RankVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(RankVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledMeshTemplate) DeepCopyInto(out *ScheduledMeshTemplate) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledMeshTemplate.
+func (in *ScheduledMeshTemplate) DeepCopy() *ScheduledMeshTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledMeshTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledMonarchMesh) DeepCopyInto(out *ScheduledMonarchMesh) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledMonarchMesh.
+func (in *ScheduledMonarchMesh) DeepCopy() *ScheduledMonarchMesh {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledMonarchMesh)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledMonarchMesh) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledMonarchMeshList) DeepCopyInto(out *ScheduledMonarchMeshList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScheduledMonarchMesh, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledMonarchMeshList.
+func (in *ScheduledMonarchMeshList) DeepCopy() *ScheduledMonarchMeshList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledMonarchMeshList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledMonarchMeshList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledMonarchMeshSpec) DeepCopyInto(out *ScheduledMonarchMeshSpec) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	in.MeshTemplate.DeepCopyInto(&out.MeshTemplate)
+	if in.SuccessfulMeshesHistoryLimit != nil {
+		in, out := &in.SuccessfulMeshesHistoryLimit, &out.SuccessfulMeshesHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedMeshesHistoryLimit != nil {
+		in, out := &in.FailedMeshesHistoryLimit, &out.FailedMeshesHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledMonarchMeshSpec.
+func (in *ScheduledMonarchMeshSpec) DeepCopy() *ScheduledMonarchMeshSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledMonarchMeshSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledMonarchMeshStatus) DeepCopyInto(out *ScheduledMonarchMeshStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledMonarchMeshStatus.
+func (in *ScheduledMonarchMeshStatus) DeepCopy() *ScheduledMonarchMeshStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledMonarchMeshStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedStorageStatus) DeepCopyInto(out *SharedStorageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedStorageStatus.
+func (in *SharedStorageStatus) DeepCopy() *SharedStorageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedStorageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSIssuerReference) DeepCopyInto(out *TLSIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSIssuerReference.
+func (in *TLSIssuerReference) DeepCopy() *TLSIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateStatus) DeepCopyInto(out *TemplateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateStatus.
+func (in *TemplateStatus) DeepCopy() *TemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Command barrier is the startup barrier injected as an init container into Monarch workers
+// when MonarchMeshSpec.StartupBarrier is set. It is shipped in the operator image.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meta-pytorch/monarch-kubernetes/internal/barrier"
+)
+
+func main() {
+	var hosts, terminationMessagePath string
+	var port int
+	var timeout, interval time.Duration
+	flag.StringVar(&hosts, "hosts", "", "Comma-separated DNS names of the peers to wait for.")
+	flag.IntVar(&port, "port", 26600, "The mesh port the peers listen on.")
+	flag.DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait for the peers before failing.")
+	flag.DurationVar(&interval, "interval", 2*time.Second, "The time between two rounds of checks.")
+	flag.StringVar(&terminationMessagePath, "termination-message-path", "/dev/termination-log",
+		"The file the progress is written to, reported by the kubelet in the pod status once the barrier exits. "+
+			"Empty disables it.")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// The workers only listen on the mesh port once all init containers have completed, so the
+	// barrier accepts connections on the port itself until it is reached by this pod.
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to listen on the mesh port: %v\n", err)
+		os.Exit(1)
+	}
+	go accept(listener)
+
+	b := &barrier.Barrier{
+		Hosts:    strings.Split(hosts, ","),
+		Port:     int32(port),
+		Interval: interval,
+		Resolver: net.DefaultResolver,
+		Dial:     (&net.Dialer{Timeout: time.Second}).DialContext,
+		Report: func(p barrier.Progress) {
+			fmt.Println(p)
+			if terminationMessagePath != "" {
+				_ = os.WriteFile(terminationMessagePath, []byte(p.String()), 0o644)
+			}
+		},
+	}
+	err = b.Wait(ctx)
+	_ = listener.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if terminationMessagePath != "" {
+			_ = os.WriteFile(terminationMessagePath, []byte(err.Error()), 0o644)
+		}
+		os.Exit(1)
+	}
+}
+
+// accept closes every connection accepted on the listener until it is closed.
+func accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Command certsync is the TLS sidecar injected into Monarch workers when MonarchMeshSpec.TLS is
+// set. It reads the certificate Secret of its own pod through the API, copies its files to the
+// directory mounted into the worker containers, and keeps them current as certificates are
+// rotated. It is shipped in the operator image.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/meta-pytorch/monarch-kubernetes/internal/certsync"
+)
+
+func main() {
+	var secret, credentials, target string
+	var interval time.Duration
+	var check bool
+	flag.StringVar(&secret, "secret", "", "The name of the certificate Secret of this pod.")
+	flag.StringVar(&credentials, "credentials", "",
+		"The directory holding the token, CA bundle and namespace used to read the Secret.")
+	flag.StringVar(&target, "target", "", "The directory the certificate files are copied to.")
+	flag.DurationVar(&interval, "interval", 30*time.Second, "The time between two checks for rotated certificates.")
+	flag.BoolVar(&check, "check", false,
+		"Exit with success if the target holds the certificate files, for use as a startup probe.")
+	flag.Parse()
+
+	if check {
+		if !certsync.Ready(target) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	config, namespace, err := certsync.Config(credentials)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load the API credentials: %v\n", err)
+		os.Exit(1)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create the API client: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := sync(ctx, clientset, namespace, secret, target); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to sync certificates: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync copies the files of the Secret to target if they changed.
+func sync(ctx context.Context, clientset kubernetes.Interface, namespace, name, target string) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	changed, err := certsync.Sync(certsync.Files(secret), target)
+	if changed {
+		fmt.Println("synced certificates from Secret", name)
+	}
+	return err
+}
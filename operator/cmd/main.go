@@ -12,15 +12,25 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	// Embed the time zone database, which the scratch image lacks, for the time zones of
+	// ScheduledMonarchMeshes.
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -29,6 +39,7 @@ import (
 
 	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
 	"github.com/meta-pytorch/monarch-kubernetes/internal/controller"
+	webhookv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -53,7 +64,10 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
+	var enforceImageVersions bool
 	var tlsOpts []func(*tls.Config)
+	meshConfig := controller.DefaultConfig()
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -71,6 +85,30 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the MonarchMesh admission webhooks are served. Requires a webhook certificate.")
+	flag.BoolVar(&enforceImageVersions, "enforce-image-versions", false,
+		"If set, the MonarchMesh validating webhook rejects meshes whose Monarch container image is not tagged "+
+			"with spec.monarchVersion. Requires --enable-webhooks.")
+	flag.BoolVar(&meshConfig.CertManagerEnabled, "enable-cert-manager", false,
+		"If set, MonarchMesh TLS certificates are issued by cert-manager unless a mesh selects another issuer.")
+	flag.StringVar(&meshConfig.VersionLabelKey, "worker-version-label", meshConfig.VersionLabelKey,
+		"The pod label carrying the Monarch version of a worker image, used to detect version skew.")
+	flag.StringVar(&meshConfig.StartupBarrierImage, "startup-barrier-image", meshConfig.StartupBarrierImage,
+		"The image providing the startup barrier binary, normally the operator image itself.")
+	flag.StringVar(&meshConfig.CertSyncImage, "cert-sync-image", meshConfig.CertSyncImage,
+		"The image providing the TLS sidecar binary of workers, normally the operator image itself.")
+	var injectedCPU, injectedMemory string
+	flag.StringVar(&injectedCPU, "injected-container-cpu-request", "",
+		"The CPU request of the startup barrier and TLS sidecar containers injected into workers, e.g. 10m.")
+	flag.StringVar(&injectedMemory, "injected-container-memory-request", "",
+		"The memory request of the startup barrier and TLS sidecar containers injected into workers, e.g. 32Mi.")
+	flag.BoolVar(&meshConfig.AdmissionQueue, "enable-admission-queue", false,
+		"If set, MonarchMeshes are only admitted once the allocatable left on the nodes fits all their workers.")
+	flag.StringVar((*string)(&meshConfig.AdmissionOrder), "admission-order", string(meshConfig.AdmissionOrder),
+		"The order MonarchMeshes are admitted in, Priority or FIFO. Only the Priority order preempts meshes.")
+	flag.DurationVar(&meshConfig.AdmissionRetryInterval, "admission-retry-interval", meshConfig.AdmissionRetryInterval,
+		"How often MonarchMeshes queued by the admission queue check the capacity of the cluster again.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -79,6 +117,31 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU: injectedCPU, corev1.ResourceMemory: injectedMemory,
+	} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			setupLog.Error(err, "invalid injected container request", "resource", name)
+			os.Exit(1)
+		}
+		if meshConfig.InjectedContainerResources.Requests == nil {
+			meshConfig.InjectedContainerResources.Requests = corev1.ResourceList{}
+		}
+		meshConfig.InjectedContainerResources.Requests[name] = quantity
+	}
+
+	switch meshConfig.AdmissionOrder {
+	case controller.AdmissionOrderPriority, controller.AdmissionOrderFIFO:
+	default:
+		setupLog.Error(nil, "invalid admission order, expected Priority or FIFO",
+			"admission-order", meshConfig.AdmissionOrder)
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -146,8 +209,19 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
+	// The operator only reads the certificate Secrets of meshes, all labeled with the mesh, so
+	// the cache does not hold every Secret of the cluster.
+	meshSecrets, err := labels.NewRequirement(meshConfig.MeshLabelKey, selection.Exists, nil)
+	if err != nil {
+		setupLog.Error(err, "invalid mesh label key", "key", meshConfig.MeshLabelKey)
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
+		Scheme: scheme,
+		Cache: cache.Options{ByObject: map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {Label: labels.NewSelector().Add(*meshSecrets)},
+		}},
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -171,13 +245,36 @@ func main() {
 	}
 
 	if err := (&controller.MonarchMeshReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Config:   meshConfig,
+		Recorder: mgr.GetEventRecorderFor("monarchmesh-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MonarchMesh")
+		os.Exit(1)
+	}
+	if err := (&controller.MonarchMeshQuotaReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-		Config: controller.DefaultConfig(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MonarchMesh")
+		setupLog.Error(err, "unable to create controller", "controller", "MonarchMeshQuota")
 		os.Exit(1)
 	}
+	if err := (&controller.ScheduledMonarchMeshReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("scheduledmonarchmesh-controller"),
+		Clock:    clock.RealClock{},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ScheduledMonarchMesh")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		if err := webhookv1alpha1.SetupMonarchMeshWebhookWithManager(mgr, enforceImageVersions); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MonarchMesh")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Package barrier implements the startup barrier run as an init container of Monarch workers.
+// It waits until the DNS names of all peers resolve and their mesh port accepts connections.
+package barrier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Resolver resolves host names. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DialFunc opens a connection to address, e.g. (&net.Dialer{}).DialContext.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Progress is the state of the barrier after a round of checks.
+type Progress struct {
+	// Total is the number of peers.
+	Total int
+	// Resolved is the number of peers whose DNS name resolves.
+	Resolved int
+	// Reachable is the number of peers accepting connections on the mesh port.
+	Reachable int
+	// Pending lists the peers that are not reachable yet.
+	Pending []string
+}
+
+// Done reports whether all peers are reachable.
+func (p Progress) Done() bool {
+	return p.Reachable == p.Total
+}
+
+// String returns a one-line summary of the progress.
+func (p Progress) String() string {
+	s := fmt.Sprintf("%d/%d peers resolved, %d/%d reachable", p.Resolved, p.Total, p.Reachable, p.Total)
+	if len(p.Pending) > 0 {
+		s += fmt.Sprintf(", waiting for %s", p.Pending[0])
+		if len(p.Pending) > 1 {
+			s += fmt.Sprintf(" and %d more", len(p.Pending)-1)
+		}
+	}
+	return s
+}
+
+// Barrier waits for a set of peers of a mesh.
+type Barrier struct {
+	// Hosts are the DNS names of the peers.
+	Hosts []string
+	// Port is the mesh port the peers listen on.
+	Port int32
+	// Interval is the time between two rounds of checks.
+	Interval time.Duration
+	// Resolver resolves the DNS names of the peers.
+	Resolver Resolver
+	// Dial checks that the mesh port of a peer accepts connections.
+	Dial DialFunc
+	// Report, if set, is called with the progress after each round of checks.
+	Report func(Progress)
+}
+
+// Check runs one round of checks. Peers are first resolved, and only resolved peers are dialed.
+func (b *Barrier) Check(ctx context.Context) Progress {
+	p := Progress{Total: len(b.Hosts)}
+	for _, host := range b.Hosts {
+		addrs, err := b.Resolver.LookupHost(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			p.Pending = append(p.Pending, host)
+			continue
+		}
+		p.Resolved++
+		conn, err := b.Dial(ctx, "tcp", net.JoinHostPort(addrs[0], strconv.Itoa(int(b.Port))))
+		if err != nil {
+			p.Pending = append(p.Pending, host)
+			continue
+		}
+		_ = conn.Close()
+		p.Reachable++
+	}
+	return p
+}
+
+// Wait checks the peers every Interval until all of them are reachable, and returns an error
+// with the last progress when ctx is done first.
+func (b *Barrier) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(b.Interval)
+	defer ticker.Stop()
+	for {
+		p := b.Check(ctx)
+		if b.Report != nil {
+			b.Report(p)
+		}
+		if p.Done() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("startup barrier not reached: %s: %w", p, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
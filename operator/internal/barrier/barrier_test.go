@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package barrier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeNetwork resolves and accepts connections for the hosts registered in it.
+type fakeNetwork struct {
+	mu        sync.Mutex
+	addrs     map[string]string
+	listening map[string]bool
+}
+
+func newFakeNetwork() *fakeNetwork {
+	return &fakeNetwork{addrs: map[string]string{}, listening: map[string]bool{}}
+}
+
+func (n *fakeNetwork) add(host, addr string, listening bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.addrs[host] = addr
+	n.listening[net.JoinHostPort(addr, "26600")] = listening
+}
+
+func (n *fakeNetwork) LookupHost(_ context.Context, host string) ([]string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if addr, ok := n.addrs[host]; ok {
+		return []string{addr}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (n *fakeNetwork) Dial(_ context.Context, _, address string) (net.Conn, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.listening[address] {
+		return nil, errors.New("connection refused")
+	}
+	client, server := net.Pipe()
+	_ = server.Close()
+	return client, nil
+}
+
+var _ = Describe("Barrier", func() {
+	var (
+		network *fakeNetwork
+		b       *Barrier
+	)
+
+	BeforeEach(func() {
+		network = newFakeNetwork()
+		b = &Barrier{
+			Hosts:    []string{"mesh-0.mesh-svc.default.svc", "mesh-1.mesh-svc.default.svc"},
+			Port:     26600,
+			Interval: time.Millisecond,
+			Resolver: network,
+			Dial:     network.Dial,
+		}
+	})
+
+	It("should only count peers that resolve and accept connections", func() {
+		network.add("mesh-0.mesh-svc.default.svc", "10.0.0.1", false)
+		p := b.Check(context.Background())
+		Expect(p).To(Equal(Progress{
+			Total: 2, Resolved: 1, Reachable: 0,
+			Pending: []string{"mesh-0.mesh-svc.default.svc", "mesh-1.mesh-svc.default.svc"},
+		}))
+		Expect(p.String()).To(Equal(
+			"1/2 peers resolved, 0/2 reachable, waiting for mesh-0.mesh-svc.default.svc and 1 more"))
+
+		network.add("mesh-0.mesh-svc.default.svc", "10.0.0.1", true)
+		network.add("mesh-1.mesh-svc.default.svc", "10.0.0.2", true)
+		p = b.Check(context.Background())
+		Expect(p.Done()).To(BeTrue())
+		Expect(p.String()).To(Equal("2/2 peers resolved, 2/2 reachable"))
+	})
+
+	It("should return once all peers are reachable", func() {
+		var reports []Progress
+		b.Report = func(p Progress) {
+			reports = append(reports, p)
+			if len(reports) == 2 {
+				network.add("mesh-0.mesh-svc.default.svc", "10.0.0.1", true)
+				network.add("mesh-1.mesh-svc.default.svc", "10.0.0.2", true)
+			}
+		}
+		Expect(b.Wait(context.Background())).To(Succeed())
+		Expect(reports).To(HaveLen(3))
+		Expect(reports[2].Done()).To(BeTrue())
+	})
+
+	It("should fail with the last progress when the timeout expires", func() {
+		network.add("mesh-0.mesh-svc.default.svc", "10.0.0.1", true)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := b.Wait(ctx)
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("1/2 peers resolved, 1/2 reachable"))
+	})
+})
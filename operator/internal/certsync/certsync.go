@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Package certsync implements the TLS sidecar of Monarch workers. All workers share one pod
+// template, so the sidecar reads the certificate Secret of its own pod through the API, with
+// credentials that only allow reading the certificate Secrets of its mesh, and copies its files
+// into a volume shared with the worker containers whenever they change.
+package certsync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dataLink is the symlink in the target directory pointing at the directory holding the current
+// files, in the layout the kubelet uses for Secret volumes.
+const dataLink = "..data"
+
+// Sync writes files into target if any of them changed. The files are written to a new
+// directory first, and dataLink is then swapped to it, so readers see either all old or all new
+// files, never a certificate together with the key of another. Each file is exposed in target
+// through a symlink into dataLink. Sync reports whether it changed target.
+func Sync(files map[string][]byte, target string) (bool, error) {
+	if len(files) == 0 {
+		return false, errors.New("no files to sync")
+	}
+	current, err := readFiles(filepath.Join(target, dataLink))
+	if err == nil && equalFiles(files, current) {
+		return false, nil
+	}
+
+	dir, err := os.MkdirTemp(target, ".."+time.Now().UTC().Format("2006_01_02_15_04_05."))
+	if err != nil {
+		return false, err
+	}
+	if err := os.Chmod(dir, 0o755); err != nil {
+		return false, err
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return false, err
+		}
+	}
+	link := filepath.Join(target, dataLink+".tmp")
+	_ = os.Remove(link)
+	if err := os.Symlink(filepath.Base(dir), link); err != nil {
+		return false, err
+	}
+	previous, _ := os.Readlink(filepath.Join(target, dataLink))
+	if err := os.Rename(link, filepath.Join(target, dataLink)); err != nil {
+		return false, err
+	}
+	for name := range files {
+		path := filepath.Join(target, name)
+		if _, err := os.Lstat(path); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(dataLink, name), path); err != nil {
+			return false, err
+		}
+	}
+	if previous != "" && previous != filepath.Base(dir) {
+		if err := os.RemoveAll(filepath.Join(target, previous)); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Ready reports whether target holds the files of a previous Sync.
+func Ready(target string) bool {
+	files, err := readFiles(filepath.Join(target, dataLink))
+	return err == nil && len(files) > 0
+}
+
+// readFiles reads the regular files of dir, following symlinks and skipping the hidden entries
+// of the kubelet and Sync layouts.
+func readFiles(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = data
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found in %s", dir)
+	}
+	return files, nil
+}
+
+// equalFiles reports whether a and b hold the same files with the same contents.
+func equalFiles(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, data := range a {
+		if other, ok := b[name]; !ok || !bytes.Equal(data, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package certsync
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Sync", func() {
+	var target string
+
+	BeforeEach(func() {
+		target = GinkgoT().TempDir()
+	})
+
+	files := func(cert, key string) map[string][]byte {
+		return map[string][]byte{"tls.crt": []byte(cert), "tls.key": []byte(key)}
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(target, name))
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	It("should write the files", func() {
+		Expect(Ready(target)).To(BeFalse())
+
+		changed, err := Sync(files("cert-1", "key-1"), target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(read("tls.crt")).To(Equal("cert-1"))
+		Expect(read("tls.key")).To(Equal("key-1"))
+		Expect(Ready(target)).To(BeTrue())
+	})
+
+	It("should only replace the files when they changed", func() {
+		_, err := Sync(files("cert-1", "key-1"), target)
+		Expect(err).NotTo(HaveOccurred())
+
+		changed, err := Sync(files("cert-1", "key-1"), target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		By("Rotating the certificate")
+		changed, err = Sync(files("cert-2", "key-2"), target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(read("tls.crt")).To(Equal("cert-2"))
+		Expect(read("tls.key")).To(Equal("key-2"))
+
+		By("Removing the directory of the previous files")
+		entries, err := os.ReadDir(target)
+		Expect(err).NotTo(HaveOccurred())
+		dirs := 0
+		for _, entry := range entries {
+			if entry.IsDir() {
+				dirs++
+			}
+		}
+		Expect(dirs).To(Equal(1))
+	})
+
+	It("should fail without files", func() {
+		_, err := Sync(nil, target)
+		Expect(err).To(HaveOccurred())
+		Expect(Ready(target)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Files", func() {
+	It("should only return the certificate, key and CA bundle of the Secret", func() {
+		secret := &corev1.Secret{Data: map[string][]byte{
+			"tls.crt": []byte("cert"), "tls.key": []byte("key"), "ca.crt": []byte("ca"), "other": []byte("other"),
+		}}
+		Expect(Files(secret)).To(Equal(map[string][]byte{
+			"tls.crt": []byte("cert"), "tls.key": []byte("key"), "ca.crt": []byte("ca"),
+		}))
+	})
+})
+
+var _ = Describe("Config", func() {
+	It("should use the projected credentials", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, NamespaceFile), []byte("team\n"), 0o644)).To(Succeed())
+		GinkgoT().Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		GinkgoT().Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+		config, namespace, err := Config(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(namespace).To(Equal("team"))
+		Expect(config.Host).To(Equal("https://10.0.0.1:443"))
+		Expect(config.BearerTokenFile).To(Equal(filepath.Join(dir, TokenFile)))
+		Expect(config.TLSClientConfig.CAFile).To(Equal(filepath.Join(dir, CAFile)))
+	})
+})
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package certsync
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// Files of the credentials directory, projected into the sidecar by the operator. They are not
+// mounted at the default location of the service account token, since the worker containers
+// may not see them.
+const (
+	TokenFile     = "token"
+	CAFile        = "ca.crt"
+	NamespaceFile = "namespace"
+)
+
+// caBundleKey is the key of the CA bundle in the certificate Secrets, as set by cert-manager.
+const caBundleKey = "ca.crt"
+
+// Config returns the configuration to reach the API server with the credentials in dir, and the
+// namespace of the pod. The token is reloaded from dir as the kubelet rotates it.
+func Config(dir string) (*rest.Config, string, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, "", errors.New("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+	}
+	namespace, err := os.ReadFile(filepath.Join(dir, NamespaceFile))
+	if err != nil {
+		return nil, "", err
+	}
+	return &rest.Config{
+		Host:            "https://" + net.JoinHostPort(host, port),
+		BearerTokenFile: filepath.Join(dir, TokenFile),
+		TLSClientConfig: rest.TLSClientConfig{CAFile: filepath.Join(dir, CAFile)},
+	}, strings.TrimSpace(string(namespace)), nil
+}
+
+// Files returns the certificate, key and CA bundle held by secret, keyed by their file name.
+func Files(secret *corev1.Secret) map[string][]byte {
+	files := make(map[string][]byte, 3)
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, caBundleKey} {
+		if data, ok := secret.Data[key]; ok {
+			files[key] = data
+		}
+	}
+	return files
+}
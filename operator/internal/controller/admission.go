@@ -0,0 +1,412 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshquota"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshtemplate"
+)
+
+// reasonPreempted is the reason of the Queued condition of meshes preempted by a mesh of higher
+// priority.
+const reasonPreempted = "Preempted"
+
+// meshPriorities resolves the priority of meshes from the PriorityClasses of the cluster.
+type meshPriorities struct {
+	classes       map[string]*schedulingv1.PriorityClass
+	globalDefault *schedulingv1.PriorityClass
+}
+
+// resolvePriorities lists the PriorityClasses of the cluster.
+func (r *MonarchMeshReconciler) resolvePriorities(ctx context.Context) (*meshPriorities, error) {
+	classes := &schedulingv1.PriorityClassList{}
+	if err := r.List(ctx, classes); err != nil {
+		return nil, err
+	}
+	priorities := &meshPriorities{classes: make(map[string]*schedulingv1.PriorityClass, len(classes.Items))}
+	for i := range classes.Items {
+		class := &classes.Items[i]
+		priorities.classes[class.Name] = class
+		if class.GlobalDefault {
+			priorities.globalDefault = class
+		}
+	}
+	return priorities, nil
+}
+
+// priority returns the priority of mesh, and whether it may preempt meshes of lower priority.
+// Like the scheduler does for pods, it falls back to the global default PriorityClass and to 0.
+func (p *meshPriorities) priority(mesh *monarchv1alpha1.MonarchMesh) (int32, bool) {
+	if mesh.Spec.Priority != nil {
+		return *mesh.Spec.Priority, true
+	}
+	class := p.globalDefault
+	if name := mesh.Spec.PodTemplate.PriorityClassName; name != "" {
+		class = p.classes[name]
+	}
+	if class == nil {
+		return 0, true
+	}
+	return class.Value, class.PreemptionPolicy == nil || *class.PreemptionPolicy != corev1.PreemptNever
+}
+
+// waitsForAdmission reports whether mesh is about to start: it is new, queued, or resumed after
+// being suspended. Meshes that already started are never queued, unless they are preempted.
+func waitsForAdmission(mesh *monarchv1alpha1.MonarchMesh) bool {
+	if mesh.Spec.Suspend || isFinished(mesh) || mesh.DeletionTimestamp != nil {
+		return false
+	}
+	switch mesh.Status.Phase {
+	case "", monarchv1alpha1.MonarchMeshQueued, monarchv1alpha1.MonarchMeshSuspended:
+		return true
+	}
+	return false
+}
+
+// admissionQueue is a snapshot of the meshes competing for admission and of what they compete
+// for: the MonarchMeshQuotas of a namespace and, with Config.AdmissionQueue, the capacity of the
+// cluster.
+type admissionQueue struct {
+	order      AdmissionOrder
+	priorities *meshPriorities
+	// meshes are the meshes of the namespace, or of the cluster when its capacity is checked.
+	meshes   []monarchv1alpha1.MonarchMesh
+	quotas   []monarchv1alpha1.MonarchMeshQuota
+	capacity *clusterCapacity
+}
+
+// admissionQueue snapshots the admission queue of mesh. It returns nil when nothing limits the
+// admission of the mesh.
+func (r *MonarchMeshReconciler) admissionQueue(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) (*admissionQueue, error) {
+	quotas := &monarchv1alpha1.MonarchMeshQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(mesh.Namespace)); err != nil {
+		return nil, err
+	}
+	if len(quotas.Items) == 0 && !r.Config.AdmissionQueue {
+		return nil, nil
+	}
+
+	queue := &admissionQueue{order: r.Config.AdmissionOrder, quotas: quotas.Items}
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	var opts []client.ListOption
+	if !r.Config.AdmissionQueue {
+		opts = append(opts, client.InNamespace(mesh.Namespace))
+	}
+	if err := r.List(ctx, meshes, opts...); err != nil {
+		return nil, err
+	}
+	// The quota and capacity the meshes need are taken from the pod specs of their workers.
+	if err := meshtemplate.ResolveAll(ctx, r.Client, meshes.Items); err != nil {
+		return nil, err
+	}
+	for i := range meshes.Items {
+		meshes.Items[i] = *r.withWorkerPodSpec(&meshes.Items[i])
+	}
+	queue.meshes = meshes.Items
+	priorities, err := r.resolvePriorities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queue.priorities = priorities
+	if r.Config.AdmissionQueue {
+		if queue.capacity, err = r.clusterCapacity(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return queue, nil
+}
+
+// before reports whether mesh a is admitted before mesh b: in creation order, after meshes of
+// higher priority unless the queue is FIFO.
+func (q *admissionQueue) before(a, b *monarchv1alpha1.MonarchMesh) bool {
+	if q.order != AdmissionOrderFIFO {
+		priorityA, _ := q.priorities.priority(a)
+		priorityB, _ := q.priorities.priority(b)
+		if priorityA != priorityB {
+			return priorityA > priorityB
+		}
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// ahead returns the meshes waiting for admission that are admitted before mesh.
+func (q *admissionQueue) ahead(mesh *monarchv1alpha1.MonarchMesh) []*monarchv1alpha1.MonarchMesh {
+	var ahead []*monarchv1alpha1.MonarchMesh
+	for i := range q.meshes {
+		other := &q.meshes[i]
+		if client.ObjectKeyFromObject(other) == client.ObjectKeyFromObject(mesh) || !waitsForAdmission(other) {
+			continue
+		}
+		if q.before(other, mesh) {
+			ahead = append(ahead, other)
+		}
+	}
+	return ahead
+}
+
+// blockers describes what keeps mesh from being admitted after the meshes ahead of it, once the
+// released meshes are gone. It returns the reason for the Queued condition, empty if the mesh
+// fits, and a message.
+//
+// Queued meshes ahead don't hold quota yet but are counted, and the capacity they can use is
+// reserved for them, as is the capacity of the workers of admitted meshes not bound to a node.
+func (q *admissionQueue) blockers(
+	mesh *monarchv1alpha1.MonarchMesh, ahead []*monarchv1alpha1.MonarchMesh, released map[types.NamespacedName]bool,
+) (string, string) {
+	var reason string
+	var messages []string
+	if len(q.quotas) > 0 {
+		var namespaceMeshes []monarchv1alpha1.MonarchMesh
+		for i := range q.meshes {
+			other := &q.meshes[i]
+			if other.Namespace == mesh.Namespace && !released[client.ObjectKeyFromObject(other)] {
+				namespaceMeshes = append(namespaceMeshes, *other)
+			}
+		}
+		usage := meshquota.Add(meshquota.Used(namespaceMeshes, mesh.Name), meshquota.Demand(mesh))
+		for _, other := range ahead {
+			if other.Namespace == mesh.Namespace && !meshquota.HoldsQuota(other) {
+				usage = meshquota.Add(usage, meshquota.Demand(other))
+			}
+		}
+		if violations := meshquota.Violations(q.quotas, usage); len(violations) > 0 {
+			descriptions := make([]string, 0, len(violations))
+			for _, violation := range violations {
+				descriptions = append(descriptions, violation.String())
+			}
+			reason = "QuotaExceeded"
+			messages = append(messages, "Waiting for quota: "+strings.Join(descriptions, "; "))
+		}
+	}
+	if q.capacity != nil {
+		free := q.capacity.free(released)
+		q.capacity.reserve(free, q.meshes, released)
+		for _, other := range ahead {
+			q.capacity.place(free, other, workerCount(other))
+		}
+		if placed := q.capacity.place(free, mesh, workerCount(mesh)); placed < workerCount(mesh) {
+			if reason == "" {
+				reason = "InsufficientCapacity"
+			}
+			messages = append(messages,
+				fmt.Sprintf("Waiting for node capacity: %d of %d workers fit", placed, workerCount(mesh)))
+		}
+	}
+	return reason, strings.Join(messages, "; ")
+}
+
+// reconcileAdmission admits meshes about to start in the order of the admission queue, against
+// the MonarchMeshQuotas of their namespace and, with Config.AdmissionQueue, the capacity of the
+// cluster. A mesh that doesn't fit after the meshes ahead of it is moved into the Queued phase
+// without workers and it returns false, unless it is at the head of a priority queue and makes
+// room by preempting meshes of lower priority.
+func (r *MonarchMeshReconciler) reconcileAdmission(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) (bool, error) {
+	if !waitsForAdmission(mesh) {
+		mesh.Status.QueuePosition = 0
+		return true, nil
+	}
+	queue, err := r.admissionQueue(ctx, mesh)
+	if err != nil {
+		return false, err
+	}
+	if queue == nil {
+		r.admit(mesh)
+		return true, nil
+	}
+
+	workers := r.withWorkerPodSpec(mesh)
+	ahead := queue.ahead(workers)
+	reason, message := queue.blockers(workers, ahead, nil)
+	if reason == "" {
+		r.admit(mesh)
+		return true, nil
+	}
+	priority, preempts := queue.priorities.priority(workers)
+	if len(ahead) == 0 && preempts && queue.order != AdmissionOrderFIFO {
+		if victims := queue.preemptionVictims(workers, priority); victims != nil {
+			if err := r.preempt(ctx, mesh, priority, victims); err != nil {
+				return false, err
+			}
+			r.admit(mesh)
+			return true, nil
+		}
+	}
+
+	if err := r.releaseWorkers(ctx, mesh); err != nil {
+		return false, err
+	}
+	r.queue(mesh, int32(len(ahead))+1, reason, message)
+	return false, nil
+}
+
+// admissionRetryInterval returns when a queued mesh checks again whether it can be admitted.
+// Quota changes are watched, but the capacity released by other pods is not.
+func (r *MonarchMeshReconciler) admissionRetryInterval() time.Duration {
+	if !r.Config.AdmissionQueue {
+		return 0
+	}
+	return r.Config.AdmissionRetryInterval
+}
+
+// admit records that a queued mesh was admitted.
+func (r *MonarchMeshReconciler) admit(mesh *monarchv1alpha1.MonarchMesh) {
+	mesh.Status.QueuePosition = 0
+	if !meta.IsStatusConditionTrue(mesh.Status.Conditions, monarchv1alpha1.MeshConditionQueued) {
+		return
+	}
+	r.recordEvent(mesh, corev1.EventTypeNormal, "Admitted", "Admitted from the admission queue")
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionQueued,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Admitted",
+		Message: "Admitted from the admission queue",
+	})
+}
+
+// queue moves mesh into the Queued phase at position. A preempted mesh keeps the reason it was
+// preempted for until it is admitted again.
+func (r *MonarchMeshReconciler) queue(mesh *monarchv1alpha1.MonarchMesh, position int32, reason, message string) {
+	mesh.Status.Phase = monarchv1alpha1.MonarchMeshQueued
+	mesh.Status.QueuePosition = position
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type: monarchv1alpha1.MeshConditionReady, Status: metav1.ConditionFalse, Reason: "Queued",
+	})
+	queued := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionQueued)
+	if queued != nil && queued.Status == metav1.ConditionTrue && queued.Reason == reasonPreempted {
+		return
+	}
+	if queued == nil || queued.Status != metav1.ConditionTrue || queued.Message != message {
+		r.recordEvent(mesh, corev1.EventTypeWarning, reason, message)
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionQueued,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// preemptionVictims returns the meshes of lower priority that mesh needs to preempt to be
+// admitted, or nil when preempting all of them would not make room. Meshes of the lowest
+// priority, then the most recently created ones, are taken first, and the victims that turn out
+// not to be needed, e.g. of another namespace than an exceeded quota, are spared.
+func (q *admissionQueue) preemptionVictims(mesh *monarchv1alpha1.MonarchMesh, priority int32) []*monarchv1alpha1.MonarchMesh {
+	var candidates []*monarchv1alpha1.MonarchMesh
+	candidatePriorities := map[types.NamespacedName]int32{}
+	for i := range q.meshes {
+		other := &q.meshes[i]
+		if client.ObjectKeyFromObject(other) == client.ObjectKeyFromObject(mesh) || !meshquota.HoldsQuota(other) {
+			continue
+		}
+		if otherPriority, _ := q.priorities.priority(other); otherPriority < priority {
+			candidates = append(candidates, other)
+			candidatePriorities[client.ObjectKeyFromObject(other)] = otherPriority
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		priorityA, priorityB := candidatePriorities[client.ObjectKeyFromObject(a)], candidatePriorities[client.ObjectKeyFromObject(b)]
+		if priorityA != priorityB {
+			return priorityA < priorityB
+		}
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	})
+
+	released := map[types.NamespacedName]bool{}
+	needed := -1
+	for i, victim := range candidates {
+		released[client.ObjectKeyFromObject(victim)] = true
+		if reason, _ := q.blockers(mesh, nil, released); reason == "" {
+			needed = i + 1
+			break
+		}
+	}
+	if needed < 0 {
+		return nil
+	}
+	for _, victim := range slices.Backward(candidates[:needed-1]) {
+		delete(released, client.ObjectKeyFromObject(victim))
+		if reason, _ := q.blockers(mesh, nil, released); reason != "" {
+			released[client.ObjectKeyFromObject(victim)] = true
+		}
+	}
+	return slices.DeleteFunc(candidates[:needed], func(victim *monarchv1alpha1.MonarchMesh) bool {
+		return !released[client.ObjectKeyFromObject(victim)]
+	})
+}
+
+// preempt queues the victims to make room for mesh. Their own reconciles scale their workers
+// down, and admit them again once they fit.
+func (r *MonarchMeshReconciler) preempt(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, priority int32, victims []*monarchv1alpha1.MonarchMesh,
+) error {
+	message := fmt.Sprintf("Preempted by MonarchMesh %s with priority %d", mesh.Name, priority)
+	names := make([]string, 0, len(victims))
+	for _, victim := range victims {
+		victim.Status.Phase = monarchv1alpha1.MonarchMeshQueued
+		victim.Status.StartTime = nil
+		meta.SetStatusCondition(&victim.Status.Conditions, metav1.Condition{
+			Type:    monarchv1alpha1.MeshConditionQueued,
+			Status:  metav1.ConditionTrue,
+			Reason:  reasonPreempted,
+			Message: message,
+		})
+		if err := r.Status().Update(ctx, victim); err != nil {
+			return err
+		}
+		r.recordEvent(victim, corev1.EventTypeWarning, reasonPreempted, message)
+		names = append(names, victim.Name)
+	}
+	r.recordEvent(mesh, corev1.EventTypeNormal, "Preempting",
+		fmt.Sprintf("Preempted MonarchMeshes of lower priority to make room: %s", strings.Join(names, ", ")))
+	return nil
+}
+
+// releaseWorkers scales the StatefulSet of a queued mesh down to zero and suspends its client Job,
+// e.g. once it was preempted.
+func (r *MonarchMeshReconciler) releaseWorkers(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) error {
+	if err := r.suspendClient(ctx, mesh, true); err != nil {
+		return err
+	}
+	ss := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, types.NamespacedName{Name: mesh.Name, Namespace: mesh.Namespace}, ss); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ptr.Deref(ss.Spec.Replicas, 1) == 0 {
+		return nil
+	}
+	patch := client.MergeFrom(ss.DeepCopy())
+	ss.Spec.Replicas = ptr.To[int32](0)
+	return r.Patch(ctx, ss, patch)
+}
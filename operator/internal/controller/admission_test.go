@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh admission queue", func() {
+	const (
+		lowName  = "admission-test-low"
+		midName  = "admission-test-mid"
+		highName = "admission-test-high"
+	)
+
+	var (
+		ctx        context.Context
+		reconciler *MonarchMeshReconciler
+		recorder   *record.FakeRecorder
+		quotaName  types.NamespacedName
+	)
+
+	key := func(name string) types.NamespacedName {
+		return types.NamespacedName{Name: name, Namespace: "default"}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: recorder,
+		}
+		quotaName = key("admission-test")
+		Expect(k8sClient.Create(ctx, &monarchv1alpha1.MonarchMeshQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: quotaName.Name, Namespace: quotaName.Namespace},
+			Spec: monarchv1alpha1.MonarchMeshQuotaSpec{
+				Replicas:        ptr.To[int32](4),
+				OverQuotaAction: monarchv1alpha1.OverQuotaQueue,
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &schedulingv1.PriorityClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "admission-test-research"},
+			Value:      1000,
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, name := range []string{lowName, midName, highName} {
+			deleteIfExists(ctx, key(name), &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+			deleteIfExists(ctx, key(name+reconciler.Config.ServiceSuffix), &corev1.Service{})
+		}
+		deleteIfExists(ctx, quotaName, &monarchv1alpha1.MonarchMeshQuota{})
+		deleteIfExists(ctx, types.NamespacedName{Name: "admission-test-research"}, &schedulingv1.PriorityClass{})
+	})
+
+	newMesh := func(name string, replicas int32, priority *int32) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(key(name), replicas)
+		mesh.Spec.Priority = priority
+		return mesh
+	}
+
+	workers := func(name string) int32 {
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key(name), ss)).To(Succeed())
+		return ptr.Deref(ss.Spec.Replicas, 0)
+	}
+
+	It("should preempt meshes of lower priority as a whole and resume them later", func() {
+		Expect(k8sClient.Create(ctx, newMesh(lowName, 4, nil))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, key(lowName))
+		Expect(workers(lowName)).To(Equal(int32(4)))
+
+		By("Admitting a mesh of a higher PriorityClass")
+		high := newMesh(highName, 2, nil)
+		high.Spec.PodTemplate.PriorityClassName = "admission-test-research"
+		Expect(k8sClient.Create(ctx, high)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, key(highName))
+		Expect(workers(highName)).To(Equal(int32(2)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Preempted by MonarchMesh " + highName)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Preempting")))
+
+		By("Scaling the preempted mesh down")
+		low := reconcileTestMesh(ctx, reconciler, key(lowName))
+		Expect(low.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+		Expect(low.Status.QueuePosition).To(Equal(int32(1)))
+		condition := meta.FindStatusCondition(low.Status.Conditions, monarchv1alpha1.MeshConditionQueued)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("Preempted"))
+		Expect(condition.Message).To(ContainSubstring("priority 1000"))
+		Expect(workers(lowName)).To(BeZero())
+
+		By("Resuming the preempted mesh once the other mesh is gone")
+		Expect(k8sClient.Delete(ctx, high)).To(Succeed())
+		low = reconcileTestMesh(ctx, reconciler, key(lowName))
+		Expect(low.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshQueued))
+		Expect(low.Status.QueuePosition).To(BeZero())
+		Expect(workers(lowName)).To(Equal(int32(4)))
+	})
+
+	It("should queue meshes in priority order without preempting meshes of higher priority", func() {
+		Expect(k8sClient.Create(ctx, newMesh(highName, 4, ptr.To[int32](10)))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, key(highName))
+		Expect(k8sClient.Create(ctx, newMesh(lowName, 1, nil))).To(Succeed())
+		Expect(k8sClient.Create(ctx, newMesh(midName, 1, ptr.To[int32](5)))).To(Succeed())
+
+		mid := reconcileTestMesh(ctx, reconciler, key(midName))
+		Expect(mid.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+		Expect(mid.Status.QueuePosition).To(Equal(int32(1)))
+		low := reconcileTestMesh(ctx, reconciler, key(lowName))
+		Expect(low.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+		Expect(low.Status.QueuePosition).To(Equal(int32(2)))
+		Expect(workers(highName)).To(Equal(int32(4)))
+	})
+})
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// startupBarrierContainerName is the name of the init container running the startup barrier.
+const startupBarrierContainerName = "monarch-startup-barrier"
+
+// defaultStartupBarrierTimeout is how long workers wait for their peers when
+// Spec.StartupBarrier.Timeout is not set.
+const defaultStartupBarrierTimeout = 10 * time.Minute
+
+// startupBarrierHosts returns the DNS names of the pods of ordinals 0 to Replicas-1.
+// They don't follow spare promotions so that promotions don't change the pod template.
+func startupBarrierHosts(mesh *monarchv1alpha1.MonarchMesh, svcName string) []string {
+	hosts := make([]string, 0, mesh.Spec.Replicas)
+	for i := int32(0); i < mesh.Spec.Replicas; i++ {
+		hosts = append(hosts, podDNSName(fmt.Sprintf("%s-%d", mesh.Name, i), svcName, mesh.Namespace))
+	}
+	return hosts
+}
+
+// injectStartupBarrier prepends the startup barrier to the init containers of the workers.
+// The barrier binary writes its progress to the termination message, which the kubelet only
+// reports in the init container status of the pod once the barrier exits. Progress while the
+// barrier waits is reported by reconcileStartupBarrier.
+func (r *MonarchMeshReconciler) injectStartupBarrier(
+	spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh, svcName string, port int32,
+) {
+	timeout := defaultStartupBarrierTimeout
+	if mesh.Spec.StartupBarrier.Timeout != nil {
+		timeout = mesh.Spec.StartupBarrier.Timeout.Duration
+	}
+	image := mesh.Spec.StartupBarrier.Image
+	if image == "" {
+		image = r.Config.StartupBarrierImage
+	}
+	barrier := corev1.Container{
+		Name:    startupBarrierContainerName,
+		Image:   image,
+		Command: []string{"/barrier"},
+		Args: []string{
+			"--hosts=" + strings.Join(startupBarrierHosts(mesh, svcName), ","),
+			fmt.Sprintf("--port=%d", port),
+			"--timeout=" + timeout.String(),
+		},
+		Resources:                *r.Config.InjectedContainerResources.DeepCopy(),
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+	}
+	spec.InitContainers = append([]corev1.Container{barrier}, spec.InitContainers...)
+}
+
+// reconcileStartupBarrier reports the progress of the startup barrier in the StartupBarrier
+// condition while workers wait in it. The barrier of a worker waits for the pods of ordinals 0
+// to Replicas-1; a peer is reachable once its pod has an IP, which the headless Service
+// publishes, and listens on the mesh port, which its own barrier or worker does.
+func (r *MonarchMeshReconciler) reconcileStartupBarrier(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) error {
+	if mesh.Spec.StartupBarrier == nil {
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionStartupBarrier)
+		return nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(mesh.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return err
+	}
+	byName := make(map[string]*corev1.Pod, len(pods.Items))
+	waiting := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		byName[pod.Name] = pod
+		if barrierRunning(pod) {
+			waiting++
+		}
+	}
+	if waiting == 0 {
+		meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+			Type:    monarchv1alpha1.MeshConditionStartupBarrier,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotWaiting",
+			Message: "No worker is waiting for its peers",
+		})
+		return nil
+	}
+
+	var unreachable []string
+	for i := int32(0); i < mesh.Spec.Replicas; i++ {
+		name := fmt.Sprintf("%s-%d", mesh.Name, i)
+		if pod, ok := byName[name]; !ok || !peerReachable(pod) {
+			unreachable = append(unreachable, name)
+		}
+	}
+	message := fmt.Sprintf("%d workers waiting for their peers, %d of %d peers reachable",
+		waiting, mesh.Spec.Replicas-int32(len(unreachable)), mesh.Spec.Replicas)
+	if len(unreachable) > 0 {
+		message += ", waiting for " + strings.Join(unreachable, ", ")
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionStartupBarrier,
+		Status:  metav1.ConditionTrue,
+		Reason:  "WaitingForPeers",
+		Message: message,
+	})
+	return nil
+}
+
+// barrierRunning reports whether the startup barrier of the pod is running.
+func barrierRunning(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name == startupBarrierContainerName {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// peerReachable reports whether the barriers of other workers can reach the pod: it has an IP
+// and its barrier or one of its containers is running.
+func peerReachable(pod *corev1.Pod) bool {
+	if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	if barrierRunning(pod) {
+		return true
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil {
+			return true
+		}
+	}
+	return false
+}
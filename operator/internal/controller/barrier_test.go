@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh startup barrier", func() {
+	const resourceName = "barrier-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		svcNamespacedName  types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		svcNamespacedName = types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, svcNamespacedName, &corev1.Service{})
+		for i := range 2 {
+			deleteIfExists(ctx, types.NamespacedName{
+				Name: fmt.Sprintf("%s-%d", resourceName, i), Namespace: "default",
+			}, &corev1.Pod{})
+		}
+	})
+
+	It("should wait for all ranks in an init container before the user init containers", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.StartupBarrier = &monarchv1alpha1.MeshStartupBarrier{
+			Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+		}
+		mesh.Spec.PodTemplate.InitContainers = []corev1.Container{{Name: "setup", Image: "busybox"}}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		initContainers := ss.Spec.Template.Spec.InitContainers
+		Expect(initContainers).To(HaveLen(2))
+		Expect(initContainers[1].Name).To(Equal("setup"))
+		barrier := initContainers[0]
+		Expect(barrier.Name).To(Equal(startupBarrierContainerName))
+		Expect(barrier.Image).To(Equal(reconciler.Config.StartupBarrierImage))
+		svc := resourceName + "-svc.default.svc"
+		Expect(barrier.Args).To(Equal([]string{
+			"--hosts=" + resourceName + "-0." + svc + "," + resourceName + "-1." + svc,
+			"--port=26600",
+			"--timeout=5m0s",
+		}))
+
+		service := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, svcNamespacedName, service)).To(Succeed())
+		Expect(service.Spec.PublishNotReadyAddresses).To(BeTrue())
+	})
+
+	It("should report the progress of the workers waiting in the barrier", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.StartupBarrier = &monarchv1alpha1.MeshStartupBarrier{}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resourceName + "-0", Namespace: "default",
+				Labels: map[string]string{
+					reconciler.Config.MeshLabelKey: resourceName,
+					reconciler.Config.AppLabelKey:  reconciler.Config.AppLabelValue,
+				},
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: startupBarrierContainerName, Image: "barrier"}},
+				Containers:     []corev1.Container{{Name: "worker", Image: "monarch:latest"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status.PodIP = "10.0.0.1"
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+			Name:  startupBarrierContainerName,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionStartupBarrier)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("WaitingForPeers"))
+		Expect(condition.Message).To(ContainSubstring("1 of 2 peers reachable"))
+		Expect(condition.Message).To(ContainSubstring(resourceName + "-1"))
+	})
+})
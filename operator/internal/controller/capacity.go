@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshquota"
+)
+
+// clusterCapacity is a snapshot of the nodes of the cluster and the pods bound to them, used by
+// the admission queue to place the workers of meshes before creating them.
+type clusterCapacity struct {
+	// nodes accepting new workers, sorted by name.
+	nodes []*corev1.Node
+	// pods are the pods bound to a node that are not terminated.
+	pods []*corev1.Pod
+	// workers counts the bound worker pods of each mesh.
+	workers map[types.NamespacedName]int32
+	// meshLabelKey is the pod label carrying the name of the mesh of a worker.
+	meshLabelKey string
+}
+
+// clusterCapacity lists the healthy, schedulable nodes of the cluster and the pods bound to them.
+func (r *MonarchMeshReconciler) clusterCapacity(ctx context.Context) (*clusterCapacity, error) {
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods); err != nil {
+		return nil, err
+	}
+
+	capacity := &clusterCapacity{workers: map[types.NamespacedName]int32{}, meshLabelKey: r.Config.MeshLabelKey}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !node.Spec.Unschedulable && len(nodeProblems(&r.Config, node)) == 0 {
+			capacity.nodes = append(capacity.nodes, node)
+		}
+	}
+	sort.Slice(capacity.nodes, func(i, j int) bool { return capacity.nodes[i].Name < capacity.nodes[j].Name })
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		capacity.pods = append(capacity.pods, pod)
+		if name, ok := pod.Labels[r.Config.MeshLabelKey]; ok &&
+			pod.Labels[r.Config.AppLabelKey] == r.Config.AppLabelValue {
+			capacity.workers[types.NamespacedName{Name: name, Namespace: pod.Namespace}]++
+		}
+	}
+	return capacity, nil
+}
+
+// free returns the allocatable left on each node once the pods bound to it are accounted for,
+// leaving out the pods of the released meshes.
+func (c *clusterCapacity) free(released map[types.NamespacedName]bool) map[string]corev1.ResourceList {
+	free := make(map[string]corev1.ResourceList, len(c.nodes))
+	for _, node := range c.nodes {
+		free[node.Name] = node.Status.Allocatable.DeepCopy()
+	}
+	for _, pod := range c.pods {
+		left, ok := free[pod.Spec.NodeName]
+		if !ok || released[types.NamespacedName{Name: pod.Labels[c.meshLabelKey], Namespace: pod.Namespace}] {
+			continue
+		}
+		subtractResources(left, podResources(&pod.Spec))
+	}
+	return free
+}
+
+// reserve places the workers of the admitted meshes that are not bound to a node yet, e.g.
+// because the StatefulSet did not create them yet.
+func (c *clusterCapacity) reserve(
+	free map[string]corev1.ResourceList, meshes []monarchv1alpha1.MonarchMesh, released map[types.NamespacedName]bool,
+) {
+	for i := range meshes {
+		mesh := &meshes[i]
+		key := types.NamespacedName{Name: mesh.Name, Namespace: mesh.Namespace}
+		if released[key] || !meshquota.HoldsQuota(mesh) || waitsForAdmission(mesh) {
+			continue
+		}
+		if missing := workerCount(mesh) - c.workers[key]; missing > 0 {
+			c.place(free, mesh, missing)
+		}
+	}
+}
+
+// place places up to count workers of mesh on the nodes accepting them, first fit, and returns
+// how many it placed. The allocatable taken by the placed workers is removed from free.
+// The worker pod template of mesh must be resolved against its Spec.TemplateRef.
+func (c *clusterCapacity) place(free map[string]corev1.ResourceList, mesh *monarchv1alpha1.MonarchMesh, count int32) int32 {
+	requests := podResources(&mesh.Spec.PodTemplate)
+	var placed int32
+	for _, node := range c.nodes {
+		if placed == count {
+			break
+		}
+		if !acceptsPod(node, &mesh.Spec.PodTemplate) {
+			continue
+		}
+		for placed < count && fitsResources(free[node.Name], requests) {
+			subtractResources(free[node.Name], requests)
+			placed++
+		}
+	}
+	return placed
+}
+
+// acceptsPod reports whether a pod with spec may be scheduled to node as far as the admission
+// queue checks: the node matches the node selector and its NoSchedule and NoExecute taints are
+// tolerated. Affinities are not checked.
+func acceptsPod(node *corev1.Node, spec *corev1.PodSpec) bool {
+	for key, value := range spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !slices.ContainsFunc(spec.Tolerations, func(toleration corev1.Toleration) bool {
+			return toleration.ToleratesTaint(taint)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// podResources returns the requests of a pod, counting the pod itself against the number of
+// pods allocatable on a node.
+func podResources(spec *corev1.PodSpec) corev1.ResourceList {
+	requests := meshquota.PodRequests(spec)
+	requests[corev1.ResourcePods] = resource.MustParse("1")
+	return requests
+}
+
+// fitsResources reports whether requests fit within free.
+func fitsResources(free, requests corev1.ResourceList) bool {
+	for name, quantity := range requests {
+		if quantity.IsZero() {
+			continue
+		}
+		if left, ok := free[name]; !ok || left.Cmp(quantity) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// subtractResources subtracts requests from free. Resources the node doesn't have are ignored.
+func subtractResources(free, requests corev1.ResourceList) {
+	for name, quantity := range requests {
+		if left, ok := free[name]; ok {
+			left.Sub(quantity)
+			free[name] = left
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh admission queue capacity", func() {
+	const (
+		runningName = "capacity-test-running"
+		queuedName  = "capacity-test-queued"
+		nodeName    = "capacity-test-node"
+		gpu         = corev1.ResourceName("nvidia.com/gpu")
+	)
+
+	var (
+		ctx        context.Context
+		reconciler *MonarchMeshReconciler
+	)
+
+	key := func(name string) types.NamespacedName {
+		return types.NamespacedName{Name: name, Namespace: "default"}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		reconciler.Config.AdmissionQueue = true
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		Expect(k8sClient.Create(ctx, node)).To(Succeed())
+		node.Status.Allocatable = corev1.ResourceList{
+			gpu:                   resource.MustParse("8"),
+			corev1.ResourcePods:   resource.MustParse("110"),
+			corev1.ResourceCPU:    resource.MustParse("64"),
+			corev1.ResourceMemory: resource.MustParse("512Gi"),
+		}
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+		Expect(k8sClient.Status().Update(ctx, node)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, name := range []string{runningName, queuedName} {
+			deleteIfExists(ctx, key(name), &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+			deleteIfExists(ctx, key(name+reconciler.Config.ServiceSuffix), &corev1.Service{})
+		}
+		deleteIfExists(ctx, types.NamespacedName{Name: nodeName}, &corev1.Node{})
+	})
+
+	newMesh := func(name string, replicas int32, priority *int32) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(key(name), replicas)
+		mesh.Spec.Priority = priority
+		mesh.Spec.PodTemplate.Containers[0].Resources.Limits = corev1.ResourceList{gpu: resource.MustParse("4")}
+		return mesh
+	}
+
+	It("should queue meshes whose workers don't fit on the nodes until capacity is released", func() {
+		Expect(k8sClient.Create(ctx, newMesh(runningName, 2, nil))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, key(runningName))
+		Expect(k8sClient.Get(ctx, key(runningName), &appsv1.StatefulSet{})).To(Succeed())
+
+		By("Reserving the capacity of the workers not created yet")
+		Expect(k8sClient.Create(ctx, newMesh(queuedName, 1, nil))).To(Succeed())
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key(queuedName)})
+		Expect(err).NotTo(HaveOccurred())
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, key(queuedName), mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+		Expect(mesh.Status.QueuePosition).To(Equal(int32(1)))
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionQueued)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("InsufficientCapacity"))
+		Expect(condition.Message).To(ContainSubstring("0 of 1 workers fit"))
+		Expect(result.RequeueAfter).To(Equal(reconciler.Config.AdmissionRetryInterval))
+		Expect(k8sClient.Get(ctx, key(queuedName), &appsv1.StatefulSet{})).NotTo(Succeed())
+
+		By("Admitting the mesh once the other mesh is gone")
+		deleteIfExists(ctx, key(runningName), &monarchv1alpha1.MonarchMesh{})
+		mesh = reconcileTestMesh(ctx, reconciler, key(queuedName))
+		Expect(mesh.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshQueued))
+		Expect(k8sClient.Get(ctx, key(queuedName), &appsv1.StatefulSet{})).To(Succeed())
+	})
+
+	It("should count the requests of the containers injected into the workers", func() {
+		reconciler.Config.InjectedContainerResources.Requests = corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("8"),
+		}
+		mesh := newTestMesh(key(queuedName), 1)
+		mesh.Spec.PodTemplate.Containers[0].Resources.Requests = corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("60"),
+		}
+		mesh.Spec.TLS = &monarchv1alpha1.MeshTLS{}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		mesh = reconcileTestMesh(ctx, reconciler, key(queuedName))
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionQueued)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("InsufficientCapacity"))
+
+		By("Admitting the mesh without the TLS sidecar")
+		mesh.Spec.TLS = nil
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		mesh = reconcileTestMesh(ctx, reconciler, key(queuedName))
+		Expect(mesh.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshQueued))
+	})
+
+	It("should not preempt meshes in FIFO order", func() {
+		reconciler.Config.AdmissionOrder = AdmissionOrderFIFO
+		Expect(k8sClient.Create(ctx, newMesh(runningName, 2, nil))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, key(runningName))
+
+		Expect(k8sClient.Create(ctx, newMesh(queuedName, 1, ptr.To[int32](100)))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, key(queuedName))
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+
+		running := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, key(runningName), running)).To(Succeed())
+		Expect(running.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshQueued))
+	})
+})
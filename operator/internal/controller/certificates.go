@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// certificateBackdate is subtracted from NotBefore to tolerate clock skew between the
+// operator and the worker nodes.
+const certificateBackdate = 5 * time.Minute
+
+// issuedCertificate is a PEM encoded certificate and private key together with the parsed certificate.
+type issuedCertificate struct {
+	cert    *x509.Certificate
+	key     crypto.Signer
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newCACertificate creates a self-signed CA certificate valid for the given duration.
+func newCACertificate(commonName string, now time.Time, duration time.Duration) (*issuedCertificate, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return issueCertificate(template, nil, now, duration)
+}
+
+// newLeafCertificate creates a certificate for the given DNS names signed by ca. Leaf certificates
+// are usable for both server and client authentication since Monarch workers connect to each other.
+func newLeafCertificate(
+	ca *issuedCertificate, commonName string, dnsNames []string, now time.Time, duration time.Duration,
+) (*issuedCertificate, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    dnsNames,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	return issueCertificate(template, ca, now, duration)
+}
+
+// issueCertificate generates a new ECDSA P-256 key and signs template with parent.
+// A nil parent produces a self-signed certificate.
+func issueCertificate(
+	template *x509.Certificate, parent *issuedCertificate, now time.Time, duration time.Duration,
+) (*issuedCertificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating private key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generating serial number: %w", err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = now.Add(-certificateBackdate)
+	template.NotAfter = now.Add(duration)
+
+	signerCert, signerKey := template, crypto.Signer(key)
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, key.Public(), signerKey)
+	if err != nil {
+		return nil, fmt.Errorf("signing certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &issuedCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// parseCertificate parses a PEM encoded certificate and, if keyPEM is non-empty, its private key.
+func parseCertificate(certPEM, keyPEM []byte) (*issuedCertificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	parsed := &issuedCertificate{cert: cert, certPEM: certPEM, keyPEM: keyPEM}
+	if len(keyPEM) == 0 {
+		return parsed, nil
+	}
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	parsed.key = key
+	return parsed, nil
+}
+
+// renewalTime returns the time at which cert should be replaced: after two thirds of its lifetime.
+func renewalTime(cert *x509.Certificate) time.Time {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotBefore.Add(lifetime * 2 / 3)
+}
+
+// needsRenewal reports whether cert is due for renewal at now.
+func needsRenewal(cert *x509.Certificate, now time.Time) bool {
+	return !now.Before(renewalTime(cert))
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// defaultCheckpointWindow is how long voluntary disruptions are held back when
+// Spec.Checkpoint.Window is not set.
+const defaultCheckpointWindow = 5 * time.Minute
+
+// Reasons of the CheckpointRequested condition.
+const (
+	checkpointReasonNoDisruption = "NoDisruption"
+	checkpointReasonWaiting      = "WaitingForAcknowledgement"
+	checkpointReasonAcknowledged = "Acknowledged"
+	checkpointReasonExpired      = "WindowExpired"
+)
+
+// disruptionCause returns why the pod is about to be disrupted, or an empty string. Pods are
+// disrupted when they carry the DisruptionTarget condition, set e.g. on preemption by the
+// scheduler or Kueue and on eviction, or when their node has a termination taint or is cordoned,
+// e.g. to be drained. Evictions rejected by the PodDisruptionBudget don't set DisruptionTarget,
+// so drains are only noticed through the cordon.
+func (r *MonarchMeshReconciler) disruptionCause(ctx context.Context, pod *corev1.Pod) (string, error) {
+	if reason, ok := disruptionTarget(pod); ok {
+		return reason, nil
+	}
+	if pod.Spec.NodeName == "" {
+		return "", nil
+	}
+	node := &corev1.Node{}
+	err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, taint := range node.Spec.Taints {
+		if slices.Contains(r.Config.TerminationTaintKeys, taint.Key) {
+			return fmt.Sprintf("node %s has taint %s", node.Name, taint.Key), nil
+		}
+	}
+	if node.Spec.Unschedulable {
+		return fmt.Sprintf("node %s is cordoned", node.Name), nil
+	}
+	return "", nil
+}
+
+// disruptionTarget returns the reason of the DisruptionTarget condition of the pod, and whether
+// the condition is set.
+func disruptionTarget(pod *corev1.Pod) (string, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.DisruptionTarget && condition.Status == corev1.ConditionTrue {
+			return condition.Reason, true
+		}
+	}
+	return "", false
+}
+
+// reconcileCheckpoint requests a checkpoint from all ranks when some of them are about to be
+// disrupted, and reports the acknowledgements in the CheckpointRequested condition. While the
+// condition is WaitingForAcknowledgement, the PodDisruptionBudget blocks voluntary disruptions.
+// It returns the number of disrupted ranks whose disruption is released since all ranks
+// acknowledged or the window expired, and the time left in the window, to check again when it
+// expires.
+func (r *MonarchMeshReconciler) reconcileCheckpoint(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) (int32, time.Duration, error) {
+	if mesh.Spec.Checkpoint == nil {
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested)
+		return 0, 0, nil
+	}
+
+	pods, causes, err := r.disruptedRanks(ctx, mesh)
+	if err != nil {
+		return 0, 0, err
+	}
+	request := mesh.Annotations[monarchv1alpha1.CheckpointRequestAnnotation]
+	if len(causes) == 0 {
+		if request != "" {
+			if err := r.setMeshAnnotation(ctx, mesh, monarchv1alpha1.CheckpointRequestAnnotation, ""); err != nil {
+				return 0, 0, err
+			}
+		}
+		meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+			Type:    monarchv1alpha1.MeshConditionCheckpointRequested,
+			Status:  metav1.ConditionFalse,
+			Reason:  checkpointReasonNoDisruption,
+			Message: "No worker is about to be disrupted",
+		})
+		return 0, 0, nil
+	}
+
+	now := time.Now()
+	if request == "" {
+		request = now.UTC().Format(time.RFC3339)
+		if err := r.setMeshAnnotation(ctx, mesh, monarchv1alpha1.CheckpointRequestAnnotation, request); err != nil {
+			return 0, 0, err
+		}
+		r.recordEvent(mesh, corev1.EventTypeWarning, "CheckpointRequested",
+			fmt.Sprintf("Requesting a checkpoint since workers are about to be disrupted: %s", strings.Join(causes, ", ")))
+	}
+	acknowledged, err := r.requestCheckpoint(ctx, pods, request)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	window, remaining := checkpointWindow(mesh, request, now)
+	condition := metav1.Condition{
+		Type:   monarchv1alpha1.MeshConditionCheckpointRequested,
+		Status: metav1.ConditionTrue,
+		Message: fmt.Sprintf("%d/%d ranks acknowledged checkpoint request %s; about to be disrupted: %s",
+			acknowledged, len(pods), request, strings.Join(causes, ", ")),
+	}
+	switch {
+	case acknowledged == len(pods):
+		condition.Reason = checkpointReasonAcknowledged
+	case remaining <= 0:
+		condition.Reason = checkpointReasonExpired
+	default:
+		condition.Reason = checkpointReasonWaiting
+	}
+	previous := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested)
+	if condition.Reason == checkpointReasonExpired && (previous == nil || previous.Reason != checkpointReasonExpired) {
+		r.recordEvent(mesh, corev1.EventTypeWarning, "CheckpointWindowExpired",
+			fmt.Sprintf("Only %d/%d ranks acknowledged the checkpoint request within %s", acknowledged, len(pods), window))
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, condition)
+	if condition.Reason == checkpointReasonWaiting {
+		return 0, remaining, nil
+	}
+	return int32(len(causes)), 0, nil
+}
+
+// disruptedRanks returns the pods backing the ranks of the mesh, and why the ones about to be
+// disrupted are.
+func (r *MonarchMeshReconciler) disruptedRanks(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) ([]*corev1.Pod, []string, error) {
+	var pods []*corev1.Pod
+	var causes []string
+	for _, rank := range mesh.Status.Ranks {
+		pod := &corev1.Pod{}
+		err := r.Get(ctx, types.NamespacedName{Name: rank.Pod, Namespace: mesh.Namespace}, pod)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		pods = append(pods, pod)
+		cause, err := r.disruptionCause(ctx, pod)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cause != "" {
+			causes = append(causes, fmt.Sprintf("rank %d (%s)", rank.Rank, cause))
+		}
+	}
+	return pods, causes, nil
+}
+
+// requestCheckpoint passes the checkpoint request to the pods that don't carry it yet, and
+// returns the number of pods that acknowledged it.
+func (r *MonarchMeshReconciler) requestCheckpoint(ctx context.Context, pods []*corev1.Pod, request string) (int, error) {
+	acknowledged := 0
+	for _, pod := range pods {
+		if pod.Annotations[monarchv1alpha1.CheckpointAckAnnotation] == request {
+			acknowledged++
+		}
+		if pod.Annotations[monarchv1alpha1.CheckpointRequestAnnotation] == request || pod.DeletionTimestamp != nil {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[monarchv1alpha1.CheckpointRequestAnnotation] = request
+		if err := r.Patch(ctx, pod, patch); err != nil && !apierrors.IsNotFound(err) {
+			return 0, err
+		}
+	}
+	return acknowledged, nil
+}
+
+// checkpointWindow returns the window of the mesh to acknowledge a checkpoint request, and the
+// time left in it at now.
+func checkpointWindow(
+	mesh *monarchv1alpha1.MonarchMesh, request string, now time.Time,
+) (time.Duration, time.Duration) {
+	window := defaultCheckpointWindow
+	if mesh.Spec.Checkpoint.Window != nil {
+		window = mesh.Spec.Checkpoint.Window.Duration
+	}
+	// A malformed request, e.g. set by hand, is treated as expired rather than blocking forever.
+	remaining := time.Duration(0)
+	if requestedAt, err := time.Parse(time.RFC3339, request); err == nil {
+		remaining = requestedAt.Add(window).Sub(now)
+	}
+	return window, remaining
+}
+
+// holdsDisruptions reports whether voluntary disruptions are held back for workers to
+// acknowledge a checkpoint request.
+func holdsDisruptions(mesh *monarchv1alpha1.MonarchMesh) bool {
+	condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested)
+	return condition != nil && condition.Reason == checkpointReasonWaiting
+}
+
+// setMeshAnnotation sets or, with an empty value, removes an annotation of the mesh. The
+// resolved spec and the status computed so far are kept, since the patch response carries the
+// stored ones.
+func (r *MonarchMeshReconciler) setMeshAnnotation(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, key, value string,
+) error {
+	spec, status := mesh.Spec.DeepCopy(), mesh.Status.DeepCopy()
+	patch := client.MergeFrom(mesh.DeepCopy())
+	if value == "" {
+		delete(mesh.Annotations, key)
+	} else {
+		if mesh.Annotations == nil {
+			mesh.Annotations = map[string]string{}
+		}
+		mesh.Annotations[key] = value
+	}
+	if err := r.Patch(ctx, mesh, patch); err != nil {
+		return err
+	}
+	mesh.Spec, mesh.Status = *spec, *status
+	return nil
+}
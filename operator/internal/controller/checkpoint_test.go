@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh checkpoint requests", func() {
+	const (
+		resourceName = "checkpoint-test-mesh"
+		nodeName     = "checkpoint-test-node"
+	)
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		podNamespacedName  types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		podNamespacedName = types.NamespacedName{Name: resourceName + "-0", Namespace: "default"}
+
+		mesh := newTestMesh(typeNamespacedName, 1)
+		mesh.Spec.Checkpoint = &monarchv1alpha1.MeshCheckpoint{Window: &metav1.Duration{Duration: time.Hour}}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName,
+			&monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{}, &policyv1.PodDisruptionBudget{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, podNamespacedName, &corev1.Pod{})
+		deleteIfExists(ctx, types.NamespacedName{Name: nodeName}, &corev1.Node{})
+	})
+
+	createWorker := func(nodeName string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podNamespacedName.Name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:   nodeName,
+				Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		return pod
+	}
+
+	maxUnavailable := func() intstr.IntOrString {
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, pdb)).To(Succeed())
+		return *pdb.Spec.MaxUnavailable
+	}
+
+	It("should hold back disruptions of preempted workers until they acknowledge", func() {
+		pod := createWorker("")
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, Reason: "PreemptionByScheduler",
+		}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		request := mesh.Annotations[monarchv1alpha1.CheckpointRequestAnnotation]
+		Expect(request).NotTo(BeEmpty())
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(checkpointReasonWaiting))
+		Expect(condition.Message).To(ContainSubstring("rank 0 (PreemptionByScheduler)"))
+		Expect(maxUnavailable()).To(Equal(intstr.FromInt32(0)))
+
+		Expect(k8sClient.Get(ctx, podNamespacedName, pod)).To(Succeed())
+		Expect(pod.Annotations).To(HaveKeyWithValue(monarchv1alpha1.CheckpointRequestAnnotation, request))
+
+		By("Releasing the disruptions once all ranks acknowledged")
+		pod.Annotations[monarchv1alpha1.CheckpointAckAnnotation] = request
+		Expect(k8sClient.Update(ctx, pod)).To(Succeed())
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested).
+			Reason).To(Equal(checkpointReasonAcknowledged))
+		Expect(maxUnavailable()).To(Equal(intstr.FromString("100%")))
+	})
+
+	It("should request a checkpoint from workers on nodes with a termination taint", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{{
+				Key: "cloud.google.com/impending-node-termination", Effect: corev1.TaintEffectNoSchedule,
+			}}},
+		})).To(Succeed())
+		createWorker(nodeName)
+
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Message).To(ContainSubstring("cloud.google.com/impending-node-termination"))
+
+		By("Clearing the request once no worker is about to be disrupted")
+		Expect(k8sClient.Delete(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}})).To(Succeed())
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Annotations).NotTo(HaveKey(monarchv1alpha1.CheckpointRequestAnnotation))
+		Expect(meta.IsStatusConditionFalse(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested)).
+			To(BeTrue())
+	})
+
+	It("should release the budget of a running mesh for drained workers once they acknowledge", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Spec:       corev1.NodeSpec{Unschedulable: true},
+		})).To(Succeed())
+		pod := createWorker(nodeName)
+
+		By("Running the mesh")
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		ss.Status.Replicas = 1
+		ss.Status.ReadyReplicas = 1
+		Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshRunning))
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal(checkpointReasonWaiting))
+		Expect(condition.Message).To(ContainSubstring("node " + nodeName + " is cordoned"))
+		Expect(maxUnavailable()).To(Equal(intstr.FromInt32(0)))
+
+		By("Allowing the disruption of the drained worker once acknowledged")
+		Expect(k8sClient.Get(ctx, podNamespacedName, pod)).To(Succeed())
+		pod.Annotations[monarchv1alpha1.CheckpointAckAnnotation] = mesh.Annotations[monarchv1alpha1.CheckpointRequestAnnotation]
+		Expect(k8sClient.Update(ctx, pod)).To(Succeed())
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshRunning))
+		Expect(meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCheckpointRequested).
+			Reason).To(Equal(checkpointReasonAcknowledged))
+		Expect(maxUnavailable()).To(Equal(intstr.FromInt32(1)))
+	})
+})
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshclass"
+)
+
+// classNameField indexes meshes by the MonarchMeshClass in effect.
+const classNameField = "status.className"
+
+// indexClassName returns the class in effect of a mesh for the classNameField index.
+func indexClassName(obj client.Object) []string {
+	mesh, ok := obj.(*monarchv1alpha1.MonarchMesh)
+	if !ok || mesh.Status.ClassName == "" {
+		return nil
+	}
+	return []string{mesh.Status.ClassName}
+}
+
+// meshesForClass maps a MonarchMeshClass to the meshes it is in effect for.
+func (r *MonarchMeshReconciler) meshesForClass(ctx context.Context, obj client.Object) []reconcile.Request {
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	if err := r.List(ctx, meshes, client.MatchingFields{classNameField: obj.GetName()}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list meshes of class", "class", obj.GetName())
+		return nil
+	}
+	return meshRequests(meshes)
+}
+
+// meshesInNamespace maps a namespace to the meshes in it, whose default and allowed classes it
+// selects.
+func (r *MonarchMeshReconciler) meshesInNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	if err := r.List(ctx, meshes, client.InNamespace(obj.GetName())); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list meshes in namespace", "namespace", obj.GetName())
+		return nil
+	}
+	return meshRequests(meshes)
+}
+
+// meshRequests returns a reconcile request for each mesh of a list.
+func meshRequests(meshes *monarchv1alpha1.MonarchMeshList) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(meshes.Items))
+	for _, mesh := range meshes.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: mesh.Name, Namespace: mesh.Namespace},
+		})
+	}
+	return requests
+}
+
+// classPolicyChanged filters namespace updates down to changes of the DefaultClassAnnotation and
+// the AllowedClassesAnnotation.
+var classPolicyChanged = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	DeleteFunc: func(event.DeleteEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldAnnotations, newAnnotations := e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()
+		return oldAnnotations[monarchv1alpha1.DefaultClassAnnotation] != newAnnotations[monarchv1alpha1.DefaultClassAnnotation] ||
+			oldAnnotations[monarchv1alpha1.AllowedClassesAnnotation] != newAnnotations[monarchv1alpha1.AllowedClassesAnnotation]
+	},
+}
+
+// reconcileClass applies the MonarchMeshClass of the mesh to the worker pod template in memory
+// and checks the mesh against the limits of the class. It returns false when the class does
+// not exist, is not allowed in the namespace or the mesh violates it, in which case the workers are left as they are until the
+// mesh complies, e.g. after the webhooks were disabled or the class was tightened.
+func (r *MonarchMeshReconciler) reconcileClass(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) (bool, error) {
+	name, err := meshclass.Name(ctx, r.Client, mesh)
+	if err != nil {
+		return false, err
+	}
+	mesh.Status.ClassName = name
+	if name == "" {
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionClassCompliant)
+		return true, nil
+	}
+
+	allowed, err := meshclass.Allowed(ctx, r.Client, mesh, name)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		r.setClassViolation(mesh, "ClassNotAllowed",
+			fmt.Sprintf("MonarchMeshClass %s is not allowed in namespace %s", name, mesh.Namespace))
+		return false, nil
+	}
+
+	class := &monarchv1alpha1.MonarchMeshClass{}
+	err = r.Get(ctx, types.NamespacedName{Name: name}, class)
+	if apierrors.IsNotFound(err) {
+		r.setClassViolation(mesh, "ClassNotFound", fmt.Sprintf("MonarchMeshClass %s does not exist", name))
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	meshclass.Apply(class, &mesh.Spec.PodTemplate)
+	if errs := meshclass.Validate(class, mesh); len(errs) > 0 {
+		r.setClassViolation(mesh, "ClassViolation", errs.ToAggregate().Error())
+		return false, nil
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionClassCompliant,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Compliant",
+		Message: fmt.Sprintf("The mesh complies with MonarchMeshClass %s", name),
+	})
+	return true, nil
+}
+
+// setClassViolation sets the ClassCompliant condition to False, recording an event when the
+// violation changed.
+func (r *MonarchMeshReconciler) setClassViolation(mesh *monarchv1alpha1.MonarchMesh, reason, message string) {
+	previous := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionClassCompliant)
+	if previous == nil || previous.Status != metav1.ConditionFalse || previous.Message != message {
+		r.recordEvent(mesh, corev1.EventTypeWarning, reason, message)
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionClassCompliant,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+}
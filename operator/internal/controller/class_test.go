@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh classes", func() {
+	const resourceName = "class-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		Expect(k8sClient.Create(ctx, &monarchv1alpha1.MonarchMeshClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "research"},
+			Spec: monarchv1alpha1.MonarchMeshClassSpec{
+				PriorityClassName: "research",
+				SchedulerName:     "gang-scheduler",
+				Tolerations:       []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+				MaxReplicas:       ptr.To[int32](2),
+			},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, types.NamespacedName{Name: "research"}, &monarchv1alpha1.MonarchMeshClass{})
+	})
+
+	newMesh := func(className string, replicas int32) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, replicas)
+		mesh.Spec.ClassName = className
+		mesh.Spec.PodTemplate.SchedulerName = "gang-scheduler"
+		return mesh
+	}
+
+	It("should apply the scheduling settings of the class to the workers", func() {
+		Expect(k8sClient.Create(ctx, newMesh("research", 2))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.ClassName).To(Equal("research"))
+		Expect(meta.IsStatusConditionTrue(mesh.Status.Conditions, monarchv1alpha1.MeshConditionClassCompliant)).
+			To(BeTrue())
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.PriorityClassName).To(Equal("research"))
+		Expect(ss.Spec.Template.Spec.SchedulerName).To(Equal("gang-scheduler"))
+		Expect(ss.Spec.Template.Spec.Tolerations).To(ContainElement(HaveField("Key", "nvidia.com/gpu")))
+	})
+
+	It("should select the default class of the namespace", func() {
+		ns := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "default"}, ns)).To(Succeed())
+		ns.Annotations = map[string]string{monarchv1alpha1.DefaultClassAnnotation: "research"}
+		Expect(k8sClient.Update(ctx, ns)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "default"}, ns)).To(Succeed())
+			delete(ns.Annotations, monarchv1alpha1.DefaultClassAnnotation)
+			Expect(k8sClient.Update(ctx, ns)).To(Succeed())
+		})
+
+		Expect(k8sClient.Create(ctx, newMesh("", 1))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.ClassName).To(Equal("research"))
+	})
+
+	It("should hold back meshes exceeding the limits of the class", func() {
+		Expect(k8sClient.Create(ctx, newMesh("research", 4))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionClassCompliant)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("ClassViolation"))
+		Expect(condition.Message).To(ContainSubstring("spec.replicas"))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, &appsv1.StatefulSet{})).NotTo(Succeed())
+	})
+
+	It("should hold back meshes of a class not allowed in the namespace", func() {
+		ns := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "default"}, ns)).To(Succeed())
+		ns.Annotations = map[string]string{monarchv1alpha1.AllowedClassesAnnotation: "batch"}
+		Expect(k8sClient.Update(ctx, ns)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "default"}, ns)).To(Succeed())
+			delete(ns.Annotations, monarchv1alpha1.AllowedClassesAnnotation)
+			Expect(k8sClient.Update(ctx, ns)).To(Succeed())
+		})
+
+		Expect(k8sClient.Create(ctx, newMesh("research", 2))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionClassCompliant)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("ClassNotAllowed"))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, &appsv1.StatefulSet{})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// Environment variables carrying the mesh discovery information into the client containers.
+const (
+	envMeshName      = "MONARCH_MESH_NAME"
+	envMeshNamespace = "MONARCH_MESH_NAMESPACE"
+	envMeshService   = "MONARCH_MESH_SERVICE"
+	envMeshPort      = "MONARCH_MESH_PORT"
+	envMeshReplicas  = "MONARCH_MESH_REPLICAS"
+	envMeshHosts     = "MONARCH_MESH_HOSTS"
+
+	// envMeshFastPathAddresses lists the address of each rank on the first of Spec.Networks.
+	envMeshFastPathAddresses = "MONARCH_MESH_FAST_PATH_ADDRESSES"
+)
+
+// clientJobName returns the name of the Job running the mesh client.
+func clientJobName(mesh *monarchv1alpha1.MonarchMesh) string {
+	return mesh.Name + "-client"
+}
+
+// clientLabels returns the labels of the client Job and its pods. They share the mesh label with
+// the workers but use a different app label so they are not selected by the headless Service.
+func (r *MonarchMeshReconciler) clientLabels(mesh *monarchv1alpha1.MonarchMesh) map[string]string {
+	return map[string]string{
+		r.Config.MeshLabelKey: mesh.Name,
+		r.Config.AppLabelKey:  r.Config.ClientAppLabelValue,
+	}
+}
+
+// reconcileClient starts the client Job once all workers are ready and finishes the mesh when the
+// Job fails, or completes with the Client completion policy. The Job is created only once: its pod
+// template is immutable, and a client that already ran must not be restarted when workers become
+// unready. The Job is stopped and resumed with the mesh by reconcileClientSuspension.
+func (r *MonarchMeshReconciler) reconcileClient(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, svcName string, port int32,
+) error {
+	job := &batchv1.Job{}
+	key := types.NamespacedName{Name: clientJobName(mesh), Namespace: mesh.Namespace}
+
+	if mesh.Spec.Client == nil {
+		job.Name, job.Namespace = key.Name, key.Namespace
+		return r.deleteOwned(ctx, mesh, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	}
+
+	err := r.Get(ctx, key, job)
+	if apierrors.IsNotFound(err) {
+		if mesh.Status.Phase != monarchv1alpha1.MonarchMeshRunning {
+			return nil
+		}
+		job = r.clientJob(mesh, svcName, port)
+		if err := ctrl.SetControllerReference(mesh, job, r.Scheme); err != nil {
+			return err
+		}
+		return r.Create(ctx, job)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			if !finishesWithClient(mesh) {
+				continue
+			}
+			finishMesh(mesh, monarchv1alpha1.MonarchMeshSucceeded, "ClientSucceeded",
+				fmt.Sprintf("Client Job %s completed", job.Name))
+		case batchv1.JobFailed:
+			finishMesh(mesh, monarchv1alpha1.MonarchMeshFailed, "ClientFailed",
+				fmt.Sprintf("Client Job %s failed: %s", job.Name, c.Message))
+		}
+	}
+	return nil
+}
+
+// clientStopped reports whether the client of the mesh must not run: the mesh finished, e.g. past
+// its deadline, was suspended, e.g. after its idle timeout, or waits in the admission queue,
+// e.g. after it was preempted.
+func clientStopped(mesh *monarchv1alpha1.MonarchMesh) bool {
+	return isFinished(mesh) || mesh.Spec.Suspend || mesh.Status.Phase == monarchv1alpha1.MonarchMeshQueued
+}
+
+// jobFinished reports whether the Job completed or failed.
+func jobFinished(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileClientSuspension suspends the client Job while the client must not run, which
+// terminates its pods, and resumes it once the mesh runs again. Finished Jobs are left alone.
+func (r *MonarchMeshReconciler) reconcileClientSuspension(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) error {
+	switch {
+	case clientStopped(mesh):
+		return r.suspendClient(ctx, mesh, true)
+	case mesh.Status.Phase == monarchv1alpha1.MonarchMeshRunning:
+		return r.suspendClient(ctx, mesh, false)
+	}
+	return nil
+}
+
+// suspendClient sets Spec.Suspend of the client Job of the mesh, if it exists and didn't finish.
+func (r *MonarchMeshReconciler) suspendClient(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, suspend bool,
+) error {
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: clientJobName(mesh), Namespace: mesh.Namespace}, job); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ptr.Deref(job.Spec.Suspend, false) == suspend || jobFinished(job) {
+		return nil
+	}
+	patch := client.MergeFrom(job.DeepCopy())
+	job.Spec.Suspend = ptr.To(suspend)
+	return r.Patch(ctx, job, patch)
+}
+
+// clientJob builds the Job running the client pod template with the mesh discovery information.
+func (r *MonarchMeshReconciler) clientJob(
+	mesh *monarchv1alpha1.MonarchMesh, svcName string, port int32,
+) *batchv1.Job {
+	labels := r.clientLabels(mesh)
+	backoffLimit := int32(0)
+	if mesh.Spec.Client.BackoffLimit != nil {
+		backoffLimit = *mesh.Spec.Client.BackoffLimit
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: clientJobName(mesh), Namespace: mesh.Namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       r.clientPodSpec(mesh, svcName, port),
+			},
+		},
+	}
+}
+
+// clientPodSpec returns the client pod template with the mesh discovery environment, the client
+// ServiceAccount from Spec.RBAC and the client certificate from Spec.TLS applied.
+func (r *MonarchMeshReconciler) clientPodSpec(
+	mesh *monarchv1alpha1.MonarchMesh, svcName string, port int32,
+) corev1.PodSpec {
+	spec := mesh.Spec.Client.Template.DeepCopy()
+	if spec.RestartPolicy == "" {
+		spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	if mesh.Spec.RBAC != nil && mesh.Spec.RBAC.ClientServiceAccountName != "" && spec.ServiceAccountName == "" {
+		spec.ServiceAccountName = mesh.Spec.RBAC.ClientServiceAccountName
+	}
+
+	// Hosts are listed in rank order at the time the client starts. Status.Ranks reports the
+	// pods backing the ranks after spares have been promoted.
+	hosts := make([]string, 0, mesh.Spec.Replicas)
+	for _, podName := range rankPodNames(mesh) {
+		hosts = append(hosts, podDNSName(podName, svcName, mesh.Namespace))
+	}
+	env := []corev1.EnvVar{
+		{Name: envMeshName, Value: mesh.Name},
+		{Name: envMeshNamespace, Value: mesh.Namespace},
+		{Name: envMeshService, Value: fmt.Sprintf("%s.%s.svc", svcName, mesh.Namespace)},
+		{Name: envMeshPort, Value: strconv.Itoa(int(port))},
+		{Name: envMeshReplicas, Value: strconv.Itoa(int(mesh.Spec.Replicas))},
+		{Name: envMeshHosts, Value: strings.Join(hosts, ",")},
+	}
+	if addresses := fastPathAddresses(mesh); addresses != nil {
+		env = append(env, corev1.EnvVar{Name: envMeshFastPathAddresses, Value: strings.Join(addresses, ",")})
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].Env = append(spec.Containers[i].Env, env...)
+	}
+
+	if mesh.Spec.Hostfile != nil && mesh.Spec.Hostfile.MountPath != "" {
+		r.injectHostfile(spec, mesh)
+	}
+	if mesh.Spec.TLS != nil {
+		r.injectClientTLS(spec, mesh)
+	}
+	return *spec
+}
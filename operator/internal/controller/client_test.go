@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh client", func() {
+	const resourceName = "client-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		jobKey             types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		jobKey = types.NamespacedName{Name: resourceName + "-client", Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, jobKey, &batchv1.Job{})
+	})
+
+	newMesh := func() *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.Client = &monarchv1alpha1.MeshClient{
+			Template: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "client", Image: "monarch:latest"}},
+			},
+		}
+		return mesh
+	}
+
+	finishJob := func(conditionType batchv1.JobConditionType) {
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+		now := metav1.Now()
+		job.Status.StartTime = &now
+		if conditionType == batchv1.JobComplete {
+			job.Status.CompletionTime = &now
+			job.Status.Succeeded = 1
+			job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+				Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue,
+			})
+		} else {
+			job.Status.Failed = 1
+			job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+				Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue,
+			})
+		}
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+			Type: conditionType, Status: corev1.ConditionTrue, Message: "test",
+		})
+		Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+	}
+
+	It("should start the client only once all workers are ready", func() {
+		Expect(k8sClient.Create(ctx, newMesh())).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		err := k8sClient.Get(ctx, jobKey, &batchv1.Job{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+		Expect(job.OwnerReferences).To(HaveLen(1))
+		Expect(*job.Spec.BackoffLimit).To(BeZero())
+		podSpec := job.Spec.Template.Spec
+		Expect(podSpec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Labels).To(HaveKeyWithValue(reconciler.Config.AppLabelKey, "monarch-client"))
+
+		svcName := resourceName + reconciler.Config.ServiceSuffix
+		env := podSpec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: envMeshReplicas, Value: "2"}))
+		Expect(env).To(ContainElement(corev1.EnvVar{
+			Name:  envMeshHosts,
+			Value: resourceName + "-0." + svcName + ".default.svc," + resourceName + "-1." + svcName + ".default.svc",
+		}))
+	})
+
+	It("should succeed the mesh and scale down workers when the client completes", func() {
+		Expect(k8sClient.Create(ctx, newMesh())).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		finishJob(batchv1.JobComplete)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshSucceeded))
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionFinished)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("ClientSucceeded"))
+
+		By("Tearing down the workers on the next reconcile")
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(*ss.Spec.Replicas).To(BeZero())
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshSucceeded))
+	})
+
+	It("should fail the mesh when the client fails", func() {
+		Expect(k8sClient.Create(ctx, newMesh())).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		finishJob(batchv1.JobFailed)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshFailed))
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionFinished)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("ClientFailed"))
+	})
+
+	It("should suspend the client when the mesh exceeds its deadline", func() {
+		mesh := newMesh()
+		mesh.Spec.ActiveDeadlineSeconds = ptr.To(int64(60))
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+		Expect(ptr.Deref(job.Spec.Suspend, false)).To(BeFalse())
+
+		By("Moving the start of the mesh past its deadline")
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Status.StartTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+		Expect(k8sClient.Status().Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshFailed))
+		Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+		Expect(ptr.Deref(job.Spec.Suspend, false)).To(BeTrue())
+	})
+
+	It("should suspend the client with the mesh and resume it once the mesh runs again", func() {
+		Expect(k8sClient.Create(ctx, newMesh())).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.Suspend = true
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+		Expect(ptr.Deref(job.Spec.Suspend, false)).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.Suspend = false
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+		Expect(ptr.Deref(job.Spec.Suspend, false)).To(BeFalse())
+	})
+})
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// isFinished reports whether the mesh reached a terminal phase.
+func isFinished(mesh *monarchv1alpha1.MonarchMesh) bool {
+	return mesh.Status.Phase == monarchv1alpha1.MonarchMeshSucceeded ||
+		mesh.Status.Phase == monarchv1alpha1.MonarchMeshFailed
+}
+
+// finishMesh moves the mesh into the terminal phase and records why in the Finished condition.
+// A mesh that already finished keeps its original outcome. The workers are scaled down by the
+// reconcile triggered by the status update.
+func finishMesh(mesh *monarchv1alpha1.MonarchMesh, phase monarchv1alpha1.MonarchMeshPhase, reason, message string) {
+	if isFinished(mesh) {
+		return
+	}
+	now := metav1.Now()
+	mesh.Status.Phase = phase
+	mesh.Status.CompletionTime = &now
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type: monarchv1alpha1.MeshConditionReady, Status: metav1.ConditionFalse, Reason: string(phase),
+	})
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionFinished,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// finishesWithClient reports whether completion of the client Job finishes the mesh.
+func finishesWithClient(mesh *monarchv1alpha1.MonarchMesh) bool {
+	return mesh.Spec.CompletionPolicy == "" || mesh.Spec.CompletionPolicy == monarchv1alpha1.CompletionPolicyClient
+}
+
+// reconcileCompletion finishes the mesh according to Spec.CompletionPolicy. Completion through
+// the client Job is handled by reconcileClient.
+func (r *MonarchMeshReconciler) reconcileCompletion(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) error {
+	if isFinished(mesh) {
+		return nil
+	}
+	switch mesh.Spec.CompletionPolicy {
+	case monarchv1alpha1.CompletionPolicyAnnotation:
+		value := mesh.Annotations[monarchv1alpha1.CompletionAnnotation]
+		switch phase := monarchv1alpha1.MonarchMeshPhase(value); phase {
+		case monarchv1alpha1.MonarchMeshSucceeded, monarchv1alpha1.MonarchMeshFailed:
+			finishMesh(mesh, phase, "AnnotationSet",
+				fmt.Sprintf("Annotation %s set to %s", monarchv1alpha1.CompletionAnnotation, value))
+		}
+	case monarchv1alpha1.CompletionPolicyWorkers:
+		// Spares only stand by for the ranks, so only the workers backing a rank are considered.
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(mesh.Namespace),
+			client.MatchingLabels(r.serviceSelector(mesh, selectorLabels))); err != nil {
+			return err
+		}
+		phase, message := workersOutcome(pods.Items, mesh.Spec.Replicas)
+		switch phase {
+		case monarchv1alpha1.MonarchMeshSucceeded:
+			finishMesh(mesh, phase, "WorkersSucceeded", message)
+		case monarchv1alpha1.MonarchMeshFailed:
+			finishMesh(mesh, phase, "WorkerFailed", message)
+		}
+	}
+	return nil
+}
+
+// workersOutcome returns Failed as soon as a worker container exited non-zero, and Succeeded once
+// all containers of all replicas exited with code 0. It returns an empty phase otherwise.
+//
+// Only the current state of each container counts: a container that crashed and runs again is
+// not done. Worker pods restart their containers since StatefulSets require RestartPolicy
+// Always, so a container waiting to be restarted is counted by its last termination state.
+//
+// Only workers that exited on their own count. Workers being deleted, e.g. by the Recreate
+// update strategy or the node health policy, and workers disrupted by an eviction or preemption
+// are killed by the kubelet and replaced by the StatefulSet.
+func workersOutcome(pods []corev1.Pod, replicas int32) (monarchv1alpha1.MonarchMeshPhase, string) {
+	succeeded := int32(0)
+	for _, pod := range pods {
+		if _, disrupted := disruptionTarget(&pod); disrupted || pod.DeletionTimestamp != nil {
+			continue
+		}
+		done := len(pod.Status.ContainerStatuses) > 0
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil && status.State.Waiting != nil {
+				terminated = status.LastTerminationState.Terminated
+			}
+			if terminated == nil {
+				done = false
+				continue
+			}
+			if terminated.ExitCode != 0 {
+				return monarchv1alpha1.MonarchMeshFailed, fmt.Sprintf("Container %s of worker %s exited with code %d",
+					status.Name, pod.Name, terminated.ExitCode)
+			}
+		}
+		if done {
+			succeeded++
+		}
+	}
+	if succeeded >= replicas {
+		return monarchv1alpha1.MonarchMeshSucceeded, fmt.Sprintf("All %d workers exited successfully", replicas)
+	}
+	return "", ""
+}
+
+// ttlRemaining returns how long a finished mesh is kept before it is deleted, and whether
+// Spec.TTLSecondsAfterFinished applies to it.
+func ttlRemaining(mesh *monarchv1alpha1.MonarchMesh) (time.Duration, bool) {
+	if !isFinished(mesh) || mesh.Spec.TTLSecondsAfterFinished == nil || mesh.Status.CompletionTime == nil {
+		return 0, false
+	}
+	ttl := time.Duration(*mesh.Spec.TTLSecondsAfterFinished) * time.Second
+	return time.Until(mesh.Status.CompletionTime.Add(ttl)), true
+}
+
+// reconcileTTL deletes a finished mesh once Spec.TTLSecondsAfterFinished has passed since its
+// completion, and reports whether it did.
+func (r *MonarchMeshReconciler) reconcileTTL(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) (bool, error) {
+	if remaining, ok := ttlRemaining(mesh); !ok || remaining > 0 {
+		return false, nil
+	}
+	err := r.Delete(ctx, mesh, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
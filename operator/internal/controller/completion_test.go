@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh completion", func() {
+	const resourceName = "completion-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		for i := range 3 {
+			deleteIfExists(ctx, types.NamespacedName{
+				Name: fmt.Sprintf("%s-%d", resourceName, i), Namespace: "default",
+			}, &corev1.Pod{})
+		}
+	})
+
+	newMesh := func(policy monarchv1alpha1.CompletionPolicy) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.CompletionPolicy = policy
+		return mesh
+	}
+
+	annotate := func(value string) {
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Annotations = map[string]string{monarchv1alpha1.CompletionAnnotation: value}
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+	}
+
+	It("should only consider rank members with the Workers policy", func() {
+		mesh := newMesh(monarchv1alpha1.CompletionPolicyWorkers)
+		mesh.Spec.Spares = 1
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		for i, exitCode := range []int32{0, 0, 1} {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fmt.Sprintf("%s-%d", resourceName, i), Namespace: "default",
+					Labels: map[string]string{
+						reconciler.Config.MeshLabelKey: resourceName,
+						reconciler.Config.AppLabelKey:  reconciler.Config.AppLabelValue,
+					},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "worker",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		}
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshSucceeded))
+	})
+
+	It("should finish the mesh when the completion annotation is set", func() {
+		Expect(k8sClient.Create(ctx, newMesh(monarchv1alpha1.CompletionPolicyAnnotation))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		annotate("Failed")
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshFailed))
+		Expect(mesh.Status.CompletionTime).NotTo(BeNil())
+
+		By("Keeping the outcome when the annotation changes")
+		annotate("Succeeded")
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshFailed))
+	})
+
+	It("should ignore the completion annotation with other policies", func() {
+		Expect(k8sClient.Create(ctx, newMesh(""))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		annotate("Succeeded")
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshPending))
+	})
+
+	It("should requeue until the TTL after finishing expires and then delete the mesh", func() {
+		mesh := newMesh(monarchv1alpha1.CompletionPolicyAnnotation)
+		mesh.Spec.TTLSecondsAfterFinished = ptr.To(int32(3600))
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		annotate("Succeeded")
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically("~", 3600e9, 60e9))
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.TTLSecondsAfterFinished = ptr.To(int32(0))
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			return errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}))
+		}).Should(BeTrue())
+	})
+})
+
+var _ = Describe("Worker completion", func() {
+	// exited returns a worker whose container exited, and is waiting to be restarted if
+	// restarting is set.
+	exited := func(name string, exitCode int32, restarting bool) corev1.Pod {
+		terminated := &corev1.ContainerStateTerminated{ExitCode: exitCode}
+		status := corev1.ContainerStatus{Name: "worker", State: corev1.ContainerState{Terminated: terminated}}
+		if restarting {
+			status.State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+			status.LastTerminationState = corev1.ContainerState{Terminated: terminated}
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+		}
+	}
+	running := func(name string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "worker", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}}},
+		}
+	}
+
+	It("should succeed once all workers exited with code 0", func() {
+		phase, _ := workersOutcome([]corev1.Pod{exited("w-0", 0, false), running("w-1")}, 2)
+		Expect(phase).To(BeEmpty())
+
+		phase, _ = workersOutcome([]corev1.Pod{exited("w-0", 0, false), exited("w-1", 0, true)}, 2)
+		Expect(phase).To(Equal(monarchv1alpha1.MonarchMeshSucceeded))
+	})
+
+	It("should fail as soon as one worker exited non-zero", func() {
+		phase, message := workersOutcome([]corev1.Pod{exited("w-0", 1, true), running("w-1")}, 2)
+		Expect(phase).To(Equal(monarchv1alpha1.MonarchMeshFailed))
+		Expect(message).To(ContainSubstring("w-0"))
+	})
+
+	It("should ignore workers that crashed and run again", func() {
+		recovered := running("w-0")
+		recovered.Status.ContainerStatuses[0].RestartCount = 1
+		recovered.Status.ContainerStatuses[0].LastTerminationState = corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: 137},
+		}
+		phase, _ := workersOutcome([]corev1.Pod{recovered, exited("w-1", 0, true)}, 2)
+		Expect(phase).To(BeEmpty())
+	})
+
+	It("should ignore workers killed by a deletion or a disruption", func() {
+		deleted := exited("w-0", 143, false)
+		deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		preempted := exited("w-1", 137, true)
+		preempted.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, Reason: "PreemptionByScheduler",
+		}}
+		phase, _ := workersOutcome([]corev1.Pod{deleted, preempted}, 2)
+		Expect(phase).To(BeEmpty())
+	})
+})
@@ -8,6 +8,12 @@
 
 package controller
 
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
 // Config holds configuration for the MonarchMesh controller.
 // These values can be overridden via controller flags in a future iteration.
 type Config struct {
@@ -24,6 +30,9 @@ type Config struct {
 	// This is used for pod selection and identification.
 	AppLabelValue string
 
+	// ClientAppLabelValue is the value for the app label on client pods run for Spec.Client.
+	ClientAppLabelValue string
+
 	// DefaultPort is the default port for Monarch mesh communication
 	// when not specified in the MonarchMesh spec.
 	DefaultPort int32
@@ -33,17 +42,147 @@ type Config struct {
 
 	// PortName is the name used for the service port.
 	PortName string
+
+	// CertManagerEnabled makes cert-manager the default issuer for meshes with Spec.TLS set.
+	CertManagerEnabled bool
+
+	// DefaultTLSDuration is the validity period of worker certificates
+	// when not specified in the MonarchMesh spec.
+	DefaultTLSDuration time.Duration
+
+	// CACertificateDuration is the validity period of the per-mesh CA issued by the operator.
+	CACertificateDuration time.Duration
+
+	// DefaultTLSMountPath is the directory the certificate of a pod is mounted at
+	// when not specified in the MonarchMesh spec.
+	DefaultTLSMountPath string
+
+	// TLSVolumeName is the name of the pod volume holding the certificate of the pod.
+	TLSVolumeName string
+
+	// CertSyncVolumeName is the name of the worker volume projecting the credentials the TLS
+	// sidecar reads the certificate Secret of its pod with, mounted only into the sidecar.
+	CertSyncVolumeName string
+
+	// CertSyncImage is the image providing the TLS sidecar binary, i.e. the operator image,
+	// which copies the certificate of a worker out of its Secret.
+	CertSyncImage string
+
+	// InjectedContainerResources are the resources of the containers the operator injects into
+	// the workers: the startup barrier and the TLS sidecar. Namespaces whose ResourceQuotas
+	// require requests need them set. They count against MonarchMeshQuotas and the capacity
+	// checked by AdmissionQueue like the containers of the pod template.
+	InjectedContainerResources corev1.ResourceRequirements
+
+	// HostfileVolumeName is the name of the pod volume holding the hostfile ConfigMap.
+	HostfileVolumeName string
+
+	// SharedStorageMountPath is the directory the claim of Spec.SharedStorage is mounted at.
+	SharedStorageMountPath string
+
+	// SharedStorageVolumeName is the name of the pod volume holding the shared storage claim.
+	SharedStorageVolumeName string
+
+	// TerminationTaintKeys are node taints announcing that the node is about to be reclaimed,
+	// e.g. spot termination notices. Workers on such nodes are asked to checkpoint when
+	// Spec.Checkpoint is set.
+	TerminationTaintKeys []string
+
+	// UnhealthyNodeConditions are node condition types that make a node unhealthy when True,
+	// in addition to the Ready condition not being True. Node problem detectors can report
+	// e.g. GPU XID errors as custom conditions.
+	UnhealthyNodeConditions []string
+
+	// UnhealthyNodeTaintKeys are node taints that make a node unhealthy, e.g. taints set by a
+	// node problem detector or remediation system.
+	UnhealthyNodeTaintKeys []string
+
+	// StartupBarrierImage is the image providing the startup barrier binary, i.e. the operator
+	// image, when not specified in the MonarchMesh spec.
+	StartupBarrierImage string
+
+	// VersionLabelKey is the pod label carrying the Monarch version of a worker image, for
+	// images whose build or admission pipeline copies the image label onto the pod.
+	// The VersionAnnotation written by the worker takes precedence.
+	VersionLabelKey string
+
+	// RoleLabelKey is the pod label marking workers as rank members or spares when
+	// Spec.Spares is set. Only members are selected by the headless Service.
+	RoleLabelKey string
+
+	// RankLabelKey is the pod label carrying the logical rank of a member worker.
+	RankLabelKey string
+
+	// AdmissionQueue admits meshes only once the allocatable left on the nodes of the cluster
+	// fits all their workers, for clusters without Kueue. Meshes that don't fit wait in the
+	// Queued phase, without a StatefulSet, and check again every AdmissionRetryInterval.
+	AdmissionQueue bool
+
+	// AdmissionOrder orders the meshes waiting for admission, against the MonarchMeshQuotas of
+	// their namespace and, with AdmissionQueue, against the capacity of the cluster.
+	AdmissionOrder AdmissionOrder
+
+	// AdmissionRetryInterval is how often meshes queued by AdmissionQueue check the capacity of
+	// the cluster again, since the allocatable released by other pods is not watched.
+	AdmissionRetryInterval time.Duration
 }
 
+// AdmissionOrder orders the meshes waiting for admission.
+type AdmissionOrder string
+
+const (
+	// AdmissionOrderPriority admits meshes of higher priority first, then in creation order.
+	// The mesh at the head of the queue preempts meshes of lower priority to make room.
+	AdmissionOrderPriority AdmissionOrder = "Priority"
+
+	// AdmissionOrderFIFO admits meshes in creation order, and never preempts.
+	AdmissionOrderFIFO AdmissionOrder = "FIFO"
+)
+
 // DefaultConfig returns the default controller configuration.
 // These defaults are suitable for most Monarch deployments.
 func DefaultConfig() Config {
 	return Config{
-		MeshLabelKey:  "monarch.pytorch.org/mesh-name",
-		AppLabelKey:   "app.kubernetes.io/name",
-		AppLabelValue: "monarch-worker",
-		DefaultPort:   26600,
-		ServiceSuffix: "-svc",
-		PortName:      "monarch",
+		MeshLabelKey:        "monarch.pytorch.org/mesh-name",
+		AppLabelKey:         "app.kubernetes.io/name",
+		AppLabelValue:       "monarch-worker",
+		ClientAppLabelValue: "monarch-client",
+		DefaultPort:         26600,
+		ServiceSuffix:       "-svc",
+		PortName:            "monarch",
+
+		DefaultTLSDuration:    90 * 24 * time.Hour,
+		CACertificateDuration: 365 * 24 * time.Hour,
+		DefaultTLSMountPath:   "/etc/monarch/tls",
+		TLSVolumeName:         "monarch-tls",
+		CertSyncVolumeName:    "monarch-cert-sync",
+		CertSyncImage:         "ghcr.io/meta-pytorch/monarch-operator:latest",
+		HostfileVolumeName:    "monarch-hostfile",
+
+		SharedStorageMountPath:  "/mnt/monarch/shared",
+		SharedStorageVolumeName: "monarch-shared",
+
+		StartupBarrierImage: "ghcr.io/meta-pytorch/monarch-operator:latest",
+		TerminationTaintKeys: []string{
+			// Set by GKE on spot and preemptible nodes.
+			"cloud.google.com/impending-node-termination",
+			// Set by the AWS Node Termination Handler on spot interruption notices.
+			"aws-node-termination-handler/spot-itn",
+			// Set by the cluster autoscaler before removing a node.
+			"ToBeDeletedByClusterAutoscaler",
+		},
+		UnhealthyNodeConditions: []string{
+			string(corev1.NodeDiskPressure),
+			string(corev1.NodeMemoryPressure),
+			string(corev1.NodePIDPressure),
+			string(corev1.NodeNetworkUnavailable),
+		},
+
+		VersionLabelKey: "monarch.pytorch.org/version",
+		RoleLabelKey:    "monarch.pytorch.org/role",
+		RankLabelKey:    "monarch.pytorch.org/rank",
+
+		AdmissionOrder:         AdmissionOrderPriority,
+		AdmissionRetryInterval: 30 * time.Second,
 	}
 }
@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// reconcileDeadlines tracks Status.StartTime and enforces Spec.ActiveDeadlineSeconds and
+// Spec.IdleTimeout. It returns the time until the next deadline expires.
+//
+// It runs before the StatefulSet is reconciled, so that an expired mesh is torn down in the
+// same reconcile. Suspending an idle mesh patches Spec.Suspend, which refreshes mesh from the
+// API server.
+func (r *MonarchMeshReconciler) reconcileDeadlines(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) (time.Duration, error) {
+	if isFinished(mesh) {
+		return 0, nil
+	}
+	if mesh.Spec.Suspend {
+		mesh.Status.StartTime = nil
+		return 0, nil
+	}
+	now := time.Now()
+	if mesh.Status.StartTime == nil {
+		mesh.Status.StartTime = &metav1.Time{Time: now}
+	}
+
+	var deadlineIn time.Duration
+	if seconds := mesh.Spec.ActiveDeadlineSeconds; seconds != nil {
+		deadline := mesh.Status.StartTime.Add(time.Duration(*seconds) * time.Second)
+		if !now.Before(deadline) {
+			message := fmt.Sprintf("Mesh was active for longer than %d seconds", *seconds)
+			finishMesh(mesh, monarchv1alpha1.MonarchMeshFailed, "DeadlineExceeded", message)
+			r.recordEvent(mesh, corev1.EventTypeWarning, "DeadlineExceeded", message)
+			return 0, nil
+		}
+		deadlineIn = deadline.Sub(now)
+	}
+
+	idleIn, err := r.reconcileIdleTimeout(ctx, mesh, now)
+	if err != nil {
+		return 0, err
+	}
+	return earliest(deadlineIn, idleIn), nil
+}
+
+// reconcileIdleTimeout suspends a Running mesh whose clients have been inactive for
+// Spec.IdleTimeout, and returns the time until it would become idle.
+func (r *MonarchMeshReconciler) reconcileIdleTimeout(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, now time.Time,
+) (time.Duration, error) {
+	if mesh.Spec.IdleTimeout == nil {
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionIdle)
+		return 0, nil
+	}
+	lastActive, err := r.lastClientActivity(ctx, mesh, now)
+	if err != nil {
+		return 0, err
+	}
+	timeout := mesh.Spec.IdleTimeout.Duration
+	idleFor := now.Sub(lastActive)
+	if mesh.Status.Phase != monarchv1alpha1.MonarchMeshRunning || idleFor < timeout {
+		meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+			Type:    monarchv1alpha1.MeshConditionIdle,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Active",
+			Message: fmt.Sprintf("Last client activity at %s", lastActive.UTC().Format(time.RFC3339)),
+		})
+		if idleFor >= timeout {
+			// The mesh is not Running yet, and is suspended by the reconcile its StatefulSet
+			// triggers once it runs. Check again after another timeout in the meantime.
+			return timeout, nil
+		}
+		return timeout - idleFor, nil
+	}
+
+	// Patch a copy, since the response would revert the resolved pod template of mesh.
+	suspended := mesh.DeepCopy()
+	suspended.Spec.Suspend = true
+	if err := r.Patch(ctx, suspended, client.MergeFrom(mesh)); err != nil {
+		return 0, err
+	}
+	mesh.Spec.Suspend = true
+	mesh.ResourceVersion = suspended.ResourceVersion
+	message := fmt.Sprintf("No client was active for %s, suspending the mesh", timeout)
+	mesh.Status.StartTime = nil
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionIdle,
+		Status:  metav1.ConditionTrue,
+		Reason:  "IdleTimeoutExceeded",
+		Message: message,
+	})
+	r.recordEvent(mesh, corev1.EventTypeWarning, "IdleTimeoutExceeded", message)
+	return 0, nil
+}
+
+// lastClientActivity returns the latest of the mesh start time and the HeartbeatAnnotation, or
+// now while the Spec.Client Job has running pods.
+func (r *MonarchMeshReconciler) lastClientActivity(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, now time.Time,
+) (time.Time, error) {
+	if mesh.Spec.Client != nil {
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Name: clientJobName(mesh), Namespace: mesh.Namespace}, job)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return time.Time{}, err
+		}
+		if err == nil && job.Status.Active > 0 {
+			return now, nil
+		}
+	}
+
+	lastActive := mesh.Status.StartTime.Time
+	if value, ok := mesh.Annotations[monarchv1alpha1.HeartbeatAnnotation]; ok {
+		heartbeat, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			logf.FromContext(ctx).Info("Ignoring malformed heartbeat annotation", "value", value)
+		} else if heartbeat.After(lastActive) {
+			lastActive = heartbeat
+		}
+	}
+	return lastActive, nil
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh deadlines", func() {
+	const resourceName = "deadline-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		recorder           *record.FakeRecorder
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: recorder,
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+	})
+
+	startedAgo := func(d time.Duration) {
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Status.StartTime = &metav1.Time{Time: time.Now().Add(-d)}
+		Expect(k8sClient.Status().Update(ctx, mesh)).To(Succeed())
+	}
+
+	It("should requeue until the active deadline and then fail the mesh", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.ActiveDeadlineSeconds = ptr.To(int64(3600))
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.StartTime).NotTo(BeNil())
+
+		startedAgo(2 * time.Hour)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshFailed))
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionFinished)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("DeadlineExceeded"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("DeadlineExceeded")))
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(*ss.Spec.Replicas).To(BeZero())
+	})
+
+	It("should suspend a Running mesh without client activity", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.IdleTimeout = &metav1.Duration{Duration: time.Hour}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		By("Keeping the mesh running while heartbeats are recent")
+		startedAgo(2 * time.Hour)
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Annotations = map[string]string{
+			monarchv1alpha1.HeartbeatAnnotation: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+		}
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Spec.Suspend).To(BeFalse())
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionIdle)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+		By("Suspending the mesh once the heartbeat is older than the idle timeout")
+		mesh.Annotations[monarchv1alpha1.HeartbeatAnnotation] =
+			time.Now().Add(-90 * time.Minute).UTC().Format(time.RFC3339)
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Spec.Suspend).To(BeTrue())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshSuspended))
+		Expect(mesh.Status.StartTime).To(BeNil())
+		condition = meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionIdle)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(recorder.Events).To(Receive(ContainSubstring("IdleTimeoutExceeded")))
+	})
+
+	It("should keep requeueing a mesh past its idle timeout until it runs", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.IdleTimeout = &metav1.Duration{Duration: time.Hour}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		startedAgo(2 * time.Hour)
+
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshPending))
+		Expect(mesh.Spec.Suspend).To(BeFalse())
+	})
+})
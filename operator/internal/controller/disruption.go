@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// reconcilePodDisruptionBudget creates, updates or removes the PodDisruptionBudget for the mesh
+// according to Spec.DisruptionPolicy, and reflects the budget in the DisruptionAllowed condition.
+//
+// A Monarch job cannot survive the loss of a single worker, so a blocking budget uses
+// maxUnavailable=0 rather than a partial budget. Unhealthy pods can always be evicted so that
+// a mesh that never became ready does not block node drains. Disruptions are also blocked while
+// workers are asked to checkpoint, see reconcileCheckpoint. Once the checkpoint is acknowledged
+// or its window expired, a blocking budget allows the released disruptions of the ranks about to
+// be disrupted.
+func (r *MonarchMeshReconciler) reconcilePodDisruptionBudget(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string, released int32,
+) error {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: mesh.Name, Namespace: mesh.Namespace},
+	}
+
+	if mesh.Spec.DisruptionPolicy == monarchv1alpha1.DisruptionPolicyNone {
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionDisruptionAllowed)
+		return r.deleteOwned(ctx, mesh, pdb)
+	}
+
+	maxUnavailable := intstr.FromString("100%")
+	switch {
+	case holdsDisruptions(mesh):
+		maxUnavailable = intstr.FromInt32(0)
+	case blocksDisruptions(mesh.Spec.DisruptionPolicy, mesh.Status.Phase):
+		maxUnavailable = intstr.FromInt32(released)
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, pdb, func() error {
+		pdb.Labels = selectorLabels
+		pdb.Spec.Selector = &metav1.LabelSelector{MatchLabels: selectorLabels}
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+		pdb.Spec.MinAvailable = nil
+		alwaysAllow := policyv1.AlwaysAllow
+		pdb.Spec.UnhealthyPodEvictionPolicy = &alwaysAllow
+		return ctrl.SetControllerReference(mesh, pdb, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionDisruptionAllowed,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Blocked",
+		Message: fmt.Sprintf("PodDisruptionBudget %s allows %d disruptions", pdb.Name, pdb.Status.DisruptionsAllowed),
+	}
+	if pdb.Status.DisruptionsAllowed > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Allowed"
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, condition)
+	return nil
+}
+
+// blocksDisruptions reports whether the given policy blocks voluntary disruptions in the given phase.
+func blocksDisruptions(policy monarchv1alpha1.DisruptionPolicy, phase monarchv1alpha1.MonarchMeshPhase) bool {
+	switch policy {
+	case monarchv1alpha1.DisruptionPolicyAllowWhenSuspended:
+		return phase != monarchv1alpha1.MonarchMeshSuspended
+	default:
+		return phase == monarchv1alpha1.MonarchMeshRunning
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh PodDisruptionBudget", func() {
+	const resourceName = "pdb-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName,
+			&monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{}, &policyv1.PodDisruptionBudget{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+	})
+
+	newMesh := func(policy monarchv1alpha1.DisruptionPolicy) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.DisruptionPolicy = policy
+		return mesh
+	}
+
+	It("should create a permissive PodDisruptionBudget while the mesh is Pending", func() {
+		Expect(k8sClient.Create(ctx, newMesh(monarchv1alpha1.DisruptionPolicyBlockWhileRunning))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, pdb)).To(Succeed())
+		Expect(pdb.Spec.Selector.MatchLabels).To(HaveKeyWithValue(reconciler.Config.MeshLabelKey, resourceName))
+		Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromString("100%"))))
+		Expect(pdb.Spec.UnhealthyPodEvictionPolicy).To(Equal(ptr.To(policyv1.AlwaysAllow)))
+		Expect(pdb.OwnerReferences).To(HaveLen(1))
+		Expect(pdb.OwnerReferences[0].Kind).To(Equal("MonarchMesh"))
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshPending))
+		Expect(meta.FindStatusCondition(mesh.Status.Conditions,
+			monarchv1alpha1.MeshConditionDisruptionAllowed)).NotTo(BeNil())
+	})
+
+	It("should block disruptions once the mesh is Running", func() {
+		Expect(k8sClient.Create(ctx, newMesh(monarchv1alpha1.DisruptionPolicyBlockWhileRunning))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		markAllReady(ctx, typeNamespacedName)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshRunning))
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, pdb)).To(Succeed())
+		Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromInt32(0))))
+
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionDisruptionAllowed)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("Blocked"))
+	})
+
+	It("should allow disruptions only when suspended with AllowWhenSuspended", func() {
+		Expect(k8sClient.Create(ctx, newMesh(monarchv1alpha1.DisruptionPolicyAllowWhenSuspended))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, pdb)).To(Succeed())
+		Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromInt32(0))))
+
+		By("Suspending the mesh")
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.Suspend = true
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshSuspended))
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(*ss.Spec.Replicas).To(BeZero())
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, pdb)).To(Succeed())
+		Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromString("100%"))))
+	})
+
+	It("should not create a PodDisruptionBudget with policy None", func() {
+		Expect(k8sClient.Create(ctx, newMesh(monarchv1alpha1.DisruptionPolicyNone))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		err := k8sClient.Get(ctx, typeNamespacedName, pdb)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(meta.FindStatusCondition(mesh.Status.Conditions,
+			monarchv1alpha1.MeshConditionDisruptionAllowed)).To(BeNil())
+	})
+})
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// Keys of the hostfile ConfigMap.
+const (
+	hostfileKey     = "hostfile"
+	hostfileJSONKey = "hostfile.json"
+)
+
+// envMeshHostfile points the containers at the plain-text hostfile when Spec.Hostfile.MountPath is set.
+const envMeshHostfile = "MONARCH_MESH_HOSTFILE"
+
+// hostfileEntry is a rank in the JSON hostfile.
+type hostfileEntry struct {
+	Rank    int32  `json:"rank"`
+	Pod     string `json:"pod"`
+	DNSName string `json:"dnsName"`
+	IP      string `json:"ip,omitempty"`
+	Port    int32  `json:"port"`
+}
+
+// hostfileDocument is the JSON hostfile.
+type hostfileDocument struct {
+	Service string          `json:"service"`
+	Port    int32           `json:"port"`
+	Ranks   []hostfileEntry `json:"ranks"`
+}
+
+// hostfileConfigMapName returns the name of the ConfigMap holding the hostfile of the mesh.
+func hostfileConfigMapName(mesh *monarchv1alpha1.MonarchMesh) string {
+	return mesh.Name + "-hostfile"
+}
+
+// hostfileData renders the rank addresses in Status.Ranks as a JSON document and as a
+// plain-text hostfile with one "<rank> <dns name> <ip> <port>" line per rank in rank order.
+// Ranks whose pod has no IP yet are listed with "-" in the plain-text format.
+func hostfileData(mesh *monarchv1alpha1.MonarchMesh, svcName string, port int32) (map[string]string, error) {
+	doc := hostfileDocument{
+		Service: fmt.Sprintf("%s.%s.svc", svcName, mesh.Namespace),
+		Port:    port,
+		Ranks:   make([]hostfileEntry, 0, len(mesh.Status.Ranks)),
+	}
+	var text strings.Builder
+	text.WriteString("# rank dns-name ip port\n")
+	for _, rank := range mesh.Status.Ranks {
+		doc.Ranks = append(doc.Ranks, hostfileEntry{
+			Rank: rank.Rank, Pod: rank.Pod, DNSName: rank.DNSName, IP: rank.IP, Port: port,
+		})
+		ip := rank.IP
+		if ip == "" {
+			ip = "-"
+		}
+		fmt.Fprintf(&text, "%d %s %s %d\n", rank.Rank, rank.DNSName, ip, port)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{hostfileKey: text.String(), hostfileJSONKey: string(data) + "\n"}, nil
+}
+
+// reconcileHostfile maintains the hostfile ConfigMap requested in Spec.Hostfile from the rank
+// addresses in Status.Ranks, and removes it when it is no longer requested.
+func (r *MonarchMeshReconciler) reconcileHostfile(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string, svcName string, port int32,
+) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: hostfileConfigMapName(mesh), Namespace: mesh.Namespace},
+	}
+	if mesh.Spec.Hostfile == nil {
+		return r.deleteOwned(ctx, mesh, cm)
+	}
+	data, err := hostfileData(mesh, svcName, port)
+	if err != nil {
+		return err
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Labels = selectorLabels
+		cm.Data = data
+		return ctrl.SetControllerReference(mesh, cm, r.Scheme)
+	})
+	return err
+}
+
+// injectHostfile mounts the hostfile ConfigMap at Spec.Hostfile.MountPath in all containers.
+// Updates to the ConfigMap are propagated to running pods by the kubelet.
+func (r *MonarchMeshReconciler) injectHostfile(spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh) {
+	mountPath := mesh.Spec.Hostfile.MountPath
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: r.Config.HostfileVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: hostfileConfigMapName(mesh)},
+			},
+		},
+	})
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+			Name: r.Config.HostfileVolumeName, MountPath: mountPath, ReadOnly: true,
+		})
+		c.Env = append(c.Env, corev1.EnvVar{Name: envMeshHostfile, Value: path.Join(mountPath, hostfileKey)})
+	}
+}
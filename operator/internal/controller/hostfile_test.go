@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh hostfile", func() {
+	const resourceName = "hostfile-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		hostfileName       types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		hostfileName = types.NamespacedName{Name: resourceName + "-hostfile", Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, hostfileName, &corev1.ConfigMap{})
+		deleteIfExists(ctx, types.NamespacedName{Name: resourceName + "-0", Namespace: "default"}, &corev1.Pod{})
+	})
+
+	It("should publish every rank in the hostfile and mount it into the workers", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.Port = 7000
+		mesh.Spec.Hostfile = &monarchv1alpha1.MeshHostfile{MountPath: "/etc/monarch/hosts"}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-0", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}}},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status.PodIP = "10.0.0.5"
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		cm := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, hostfileName, cm)).To(Succeed())
+		svc := resourceName + "-svc.default.svc"
+		Expect(cm.Data).To(HaveKeyWithValue(hostfileKey, "# rank dns-name ip port\n"+
+			"0 "+resourceName+"-0."+svc+" 10.0.0.5 7000\n"+
+			"1 "+resourceName+"-1."+svc+" - 7000\n"))
+		var doc hostfileDocument
+		Expect(json.Unmarshal([]byte(cm.Data[hostfileJSONKey]), &doc)).To(Succeed())
+		Expect(doc.Service).To(Equal(svc))
+		Expect(doc.Ranks).To(Equal([]hostfileEntry{
+			{Rank: 0, Pod: resourceName + "-0", DNSName: resourceName + "-0." + svc, IP: "10.0.0.5", Port: 7000},
+			{Rank: 1, Pod: resourceName + "-1", DNSName: resourceName + "-1." + svc, Port: 7000},
+		}))
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("ConfigMap.Name", hostfileName.Name)))
+		Expect(ss.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+			corev1.EnvVar{Name: envMeshHostfile, Value: "/etc/monarch/hosts/hostfile"}))
+
+		By("Removing the ConfigMap once the hostfile is no longer requested")
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.Hostfile = nil
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		err := k8sClient.Get(ctx, hostfileName, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
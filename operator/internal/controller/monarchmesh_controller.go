@@ -11,16 +11,26 @@ package controller
 import (
 	"context"
 	"maps"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
@@ -29,8 +39,9 @@ import (
 // MonarchMeshReconciler reconciles a MonarchMesh object
 type MonarchMeshReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Config Config
+	Scheme   *runtime.Scheme
+	Config   Config
+	Recorder record.EventRecorder
 }
 
 // RBAC permissions for the controller.
@@ -55,12 +66,106 @@ type MonarchMeshReconciler struct {
 // services (get;list;watch;create;update;patch;delete):
 //   The controller creates a headless Service for each MonarchMesh. The headless Service
 //   enables DNS-based pod discovery (e.g., mesh-0.mesh-svc.namespace.svc.cluster.local).
+//
+// poddisruptionbudgets (get;list;watch;create;update;patch;delete):
+//   The controller creates a PodDisruptionBudget for each MonarchMesh according to
+//   Spec.DisruptionPolicy, so node drains don't evict workers of a running mesh.
+//
+// networkpolicies (get;list;watch;create;update;patch;delete):
+//   When Spec.NetworkIsolation is set, the controller creates a NetworkPolicy that only admits
+//   traffic to the mesh port from workers of the same mesh and from selected client pods.
+//
+// secrets (get;list;watch;create;update;patch;delete):
+//   When Spec.TLS is set, the controller stores the per-mesh CA in a Secret, and the
+//   certificate of every worker pod and of the client in a Secret of its own, and rotates them
+//   before they expire.
+//
+// certificates, issuers [cert-manager.io] (get;list;watch;create;update;patch;delete):
+//   When Spec.TLS delegates issuing to cert-manager, the controller creates a Certificate for
+//   every worker pod and for the client and, unless an issuer is referenced, a CA Issuer backed
+//   by the per-mesh CA.
+//
+// serviceaccounts, roles, rolebindings (get;list;watch;create;update;patch;delete):
+//   When Spec.RBAC is set, the controller creates a ServiceAccount for the workers and a Role
+//   and RoleBinding granting a client ServiceAccount read access to this mesh only.
+//
+// pods (get;list;watch;patch;delete):
+//   Required to grant read access to worker pods in the per-mesh client Role, since RBAC
+//   only allows granting permissions the controller holds itself. With the Workers completion
+//   policy, the controller also reads worker exit codes to finish the mesh. With the Recreate
+//   update strategy, the controller deletes all outdated workers at once. Worker pods are
+//   watched to detect version skew against Spec.MonarchVersion. With Spec.Spares, the
+//   controller labels workers as rank members or spares to promote spares into failed ranks.
+//   With Spec.Checkpoint, the controller annotates workers with checkpoint requests. With the
+//   Recreate startup timeout action, the controller deletes workers stuck starting. With the
+//   Replace unhealthy node policy, the controller deletes the workers on unhealthy nodes, and
+//   releases new workers from their scheduling gate with a node affinity avoiding these nodes.
+//
+// jobs (get;list;watch;create;update;patch;delete):
+//   When Spec.Client is set, the controller runs the Monarch client as a Job once all workers
+//   are ready, and finishes the mesh when the Job completes or fails.
+//
+// events (create;patch):
+//   The controller records events when it fails a mesh past Spec.ActiveDeadlineSeconds,
+//   suspends it after Spec.IdleTimeout, recreates workers, or detects version skew.
+//
+// nodes (get;list;watch):
+//   The controller reports the topology domain of each rank from the labels of its node, and
+//   watches the taints and conditions of the nodes hosting workers to detect impending node
+//   terminations and unhealthy nodes. With the Replace unhealthy node policy, the controller
+//   keeps new workers off the unhealthy nodes of the cluster.
+//
+// configmaps (get;list;watch;create;update;patch;delete):
+//   When Spec.Hostfile is set, the controller publishes the address of each rank in a
+//   ConfigMap that can be mounted into the worker and client pods.
+//
+// persistentvolumeclaims (get;list;watch;create;update;patch):
+//   The controller reports the binding of the claims created for each rank from
+//   Spec.VolumeClaimTemplates. With Spec.SharedStorage, it creates or adopts the shared claim
+//   and adds or removes the mesh as its owner according to the retention policy.
+//
+// monarchmeshtemplates, clustermonarchmeshtemplates (get;list;watch):
+//   When Spec.TemplateRef is set, the controller merges the mesh pod template over the
+//   referenced template, and watches templates to update the meshes referencing them.
+//
+// monarchmeshclasses, namespaces (get;list;watch):
+//   The controller and the admission webhooks apply the MonarchMeshClass selected by
+//   Spec.ClassName or by the default class annotation of the namespace of the mesh.
+//
+// monarchmeshquotas (get;list;watch):
+//   The controller and the validating webhook check meshes about to start against the quotas
+//   of their namespace, and the controller queues meshes that would exceed them.
+//
+// nodes, pods (list):
+//   With Config.AdmissionQueue, the controller places the workers of meshes about to start on
+//   the allocatable left by the pods bound to each node before creating them.
+//
+// priorityclasses (get;list;watch):
+//   Meshes without Spec.Priority take the priority of the PriorityClass of their workers in the
+//   admission queue. The controller preempts meshes of lower priority by updating their status.
 
 // +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshes/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates;issuers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshtemplates;clustermonarchmeshtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshquotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
 
 // Reconcile ensures the cluster state matches the desired state specified in the MonarchMesh resource.
 // It creates/updates a headless Service for DNS-based pod discovery and a StatefulSet for running
@@ -79,7 +184,14 @@ func (r *MonarchMeshReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// 2. Define identifiers and labels for owned resources.
+	// 2. Decide whether the mesh may run workers: garbage-collect it after its TTL, resolve its
+	// template and class, admit it, and enforce its deadlines.
+	result, deadlineIn, err := r.reconcilePreAdmission(ctx, &mesh)
+	if err != nil || result != nil {
+		return ptr.Deref(result, ctrl.Result{}), err
+	}
+
+	// 3. Define identifiers and labels for owned resources.
 	// Uses FQDN label convention to avoid collisions per:
 	// https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
 	//
@@ -91,84 +203,398 @@ func (r *MonarchMeshReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		r.Config.AppLabelKey:  r.Config.AppLabelValue,
 	}
 
-	// labels merges user-provided labels from MonarchMesh with controller-managed labels.
-	// Controller-managed labels take precedence to ensure selectors work correctly.
-	// Only applied to StatefulSet metadata for Kueue integration.
-	labels := mergeLabels(mesh.Labels, selectorLabels, log)
-
 	svcName := mesh.Name + r.Config.ServiceSuffix
+	port := r.meshPort(&mesh)
+
+	// 4. Ensure the Service, the resources the workers depend on, and the StatefulSet exist.
+	ss, renewIn, err := r.reconcileOwnedResources(ctx, &mesh, selectorLabels, svcName, port)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 5. Report the pod, node, addresses and volumes backing each rank, and replace the ranks
+	// on unhealthy nodes.
+	if err := r.reconcileRanks(ctx, &mesh, selectorLabels, svcName, port); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 6. Compute MonarchMesh status from the observed state of the StatefulSet.
+	// Status updates are triggered automatically when owned StatefulSet changes (via Owns()).
+	updateStatus(&mesh, ss)
+
+	// 7. Act on the health of the workers: update, time out and complete them, and guard
+	// them against disruptions.
+	workersIn, err := r.reconcileWorkerHealth(ctx, &mesh, ss, selectorLabels, svcName, port)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 8. Persist the computed status.
+	if err := r.Status().Update(ctx, &mesh); err != nil {
+		log.Error(err, "Failed to update MonarchMesh status")
+		return ctrl.Result{}, err
+	}
+
+	// Requeue in time to rotate certificates before they expire, to enforce deadlines, and to
+	// delete the mesh once its TTL after finishing expires.
+	ttlIn, _ := ttlRemaining(&mesh)
+	return ctrl.Result{RequeueAfter: earliest(renewIn, deadlineIn, ttlIn, workersIn)}, nil
+}
+
+// reconcilePreAdmission runs the steps deciding whether the mesh may run workers before any
+// owned resource is touched. It returns the result to end the reconciliation with when the mesh
+// was deleted or waits, and otherwise the time until the next deadline of the mesh.
+func (r *MonarchMeshReconciler) reconcilePreAdmission(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) (*ctrl.Result, time.Duration, error) {
+	log := logf.FromContext(ctx)
 
-	// Determine the port to use (default from config if not specified in CRD)
-	port := mesh.Spec.Port
-	if port == 0 {
-		port = r.Config.DefaultPort
+	// Garbage-collect the mesh once Spec.TTLSecondsAfterFinished expired.
+	deleted, err := r.reconcileTTL(ctx, mesh)
+	if err != nil {
+		log.Error(err, "Failed to delete finished MonarchMesh")
+		return nil, 0, err
+	}
+	if deleted {
+		log.Info("Deleted finished MonarchMesh after its TTL expired")
+		return &ctrl.Result{}, 0, nil
+	}
+
+	// Merge Spec.PodTemplate over the template referenced by Spec.TemplateRef.
+	// Meshes referencing a missing template wait for it without touching their workers.
+	resolved, err := r.reconcileTemplate(ctx, mesh)
+	if err != nil {
+		log.Error(err, "Failed to resolve template")
+		return nil, 0, err
+	}
+	if !resolved {
+		return r.waitBeforeWorkers(ctx, mesh, ctrl.Result{})
+	}
+
+	// Apply the MonarchMeshClass of the mesh and check the mesh against its limits.
+	// Meshes violating their class wait until they comply without touching their workers.
+	compliant, err := r.reconcileClass(ctx, mesh)
+	if err != nil {
+		log.Error(err, "Failed to apply MonarchMeshClass")
+		return nil, 0, err
+	}
+	if !compliant {
+		return r.waitBeforeWorkers(ctx, mesh, ctrl.Result{})
+	}
+
+	// Admit meshes about to start against the MonarchMeshQuotas of their namespace and, with
+	// the admission queue enabled, the capacity of the cluster, preempting meshes of lower
+	// priority to make room. The resolved pod template tells what the workers request.
+	// Queued meshes wait without workers, and their deadlines don't run.
+	admitted, err := r.reconcileAdmission(ctx, mesh)
+	if err != nil {
+		log.Error(err, "Failed to admit MonarchMesh")
+		return nil, 0, err
+	}
+	if !admitted {
+		return r.waitBeforeWorkers(ctx, mesh, ctrl.Result{RequeueAfter: r.admissionRetryInterval()})
+	}
+
+	// Enforce Spec.ActiveDeadlineSeconds and Spec.IdleTimeout before creating any workers.
+	deadlineIn, err := r.reconcileDeadlines(ctx, mesh)
+	if err != nil {
+		log.Error(err, "Failed to enforce deadlines")
+		return nil, 0, err
+	}
+	return nil, deadlineIn, nil
+}
+
+// waitBeforeWorkers persists the status of a mesh that waits before running workers, and returns
+// the result to end the reconciliation with.
+func (r *MonarchMeshReconciler) waitBeforeWorkers(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, result ctrl.Result,
+) (*ctrl.Result, time.Duration, error) {
+	if err := r.Status().Update(ctx, mesh); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to update MonarchMesh status")
+		return nil, 0, err
+	}
+	return &result, 0, nil
+}
+
+// reconcileOwnedResources ensures the headless Service, the resources the workers depend on and
+// the StatefulSet of the workers exist, in that order. It returns the StatefulSet and the time
+// until the TLS certificates must be renewed.
+func (r *MonarchMeshReconciler) reconcileOwnedResources(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+	svcName string, port int32,
+) (*appsv1.StatefulSet, time.Duration, error) {
+	log := logf.FromContext(ctx)
+
+	// Ensure headless Service exists for DNS-based pod discovery.
+	if err := r.reconcileService(ctx, mesh, selectorLabels, svcName, port); err != nil {
+		log.Error(err, "Failed to create or update Service")
+		// Returning error automatically triggers requeue with exponential backoff
+		return nil, 0, err
+	}
+
+	// Ensure the NetworkPolicy isolating mesh traffic matches Spec.NetworkIsolation.
+	if err := r.reconcileNetworkPolicy(ctx, mesh, selectorLabels, port); err != nil {
+		log.Error(err, "Failed to reconcile NetworkPolicy")
+		return nil, 0, err
+	}
+
+	// Ensure the worker ServiceAccount and client RBAC match Spec.RBAC.
+	if err := r.reconcileRBAC(ctx, mesh, selectorLabels, svcName); err != nil {
+		log.Error(err, "Failed to reconcile RBAC")
+		return nil, 0, err
+	}
+
+	// Ensure TLS certificates exist before the pods that mount them.
+	renewIn, err := r.reconcileTLS(ctx, mesh, svcName)
+	if err != nil {
+		log.Error(err, "Failed to reconcile TLS certificates")
+		return nil, 0, err
+	}
+
+	// Ensure the shared storage claim exists before the pods that mount it.
+	if err := r.reconcileSharedStorage(ctx, mesh, selectorLabels); err != nil {
+		log.Error(err, "Failed to reconcile shared storage")
+		return nil, 0, err
 	}
 
-	// 3. Ensure headless Service exists for DNS-based pod discovery.
-	// The headless Service (ClusterIP: None) provides DNS entries like:
-	// <pod-name>.<service-name>.<namespace>.svc.cluster.local
+	// Ensure StatefulSet exists for running Monarch worker pods.
+	ss, err := r.reconcileStatefulSet(ctx, mesh, selectorLabels, svcName, port)
+	if err != nil {
+		log.Error(err, "Failed to create or update StatefulSet")
+		return nil, 0, err
+	}
+	return ss, renewIn, nil
+}
+
+// reconcileService ensures the headless Service (ClusterIP: None) of the mesh exists. It
+// provides DNS entries like <pod-name>.<service-name>.<namespace>.svc.cluster.local
+func (r *MonarchMeshReconciler) reconcileService(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+	svcName string, port int32,
+) error {
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: mesh.Namespace},
 	}
 	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
 		svc.Labels = selectorLabels
 		svc.Spec.ClusterIP = "None"
-		svc.Spec.Selector = selectorLabels
+		svc.Spec.Selector = r.serviceSelector(mesh, selectorLabels)
 		svc.Spec.Ports = []corev1.ServicePort{{Name: r.Config.PortName, Port: port}}
-		return ctrl.SetControllerReference(&mesh, svc, r.Scheme)
+		// Workers waiting in the startup barrier are not ready but must be resolvable by their peers.
+		svc.Spec.PublishNotReadyAddresses = mesh.Spec.StartupBarrier != nil
+		return ctrl.SetControllerReference(mesh, svc, r.Scheme)
 	})
-	if err != nil {
-		log.Error(err, "Failed to create or update Service")
-		// Returning error automatically triggers requeue with exponential backoff
-		return ctrl.Result{}, err
-	}
+	return err
+}
+
+// reconcileStatefulSet ensures the StatefulSet running the Monarch worker pods exists.
+// We use StatefulSet (not Deployment) because:
+// - Pods get stable, predictable names (mesh-0, mesh-1, etc.)
+// - Pods maintain identity across restarts
+func (r *MonarchMeshReconciler) reconcileStatefulSet(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+	svcName string, port int32,
+) (*appsv1.StatefulSet, error) {
+	// labels merges user-provided labels from MonarchMesh with controller-managed labels.
+	// Controller-managed labels take precedence to ensure selectors work correctly.
+	// Only applied to StatefulSet metadata for Kueue integration.
+	labels := mergeLabels(mesh.Labels, selectorLabels, logf.FromContext(ctx))
 
-	// 4. Ensure StatefulSet exists for running Monarch worker pods.
-	// We use StatefulSet (not Deployment) because:
-	// - Pods get stable, predictable names (mesh-0, mesh-1, etc.)
-	// - Pods maintain identity across restarts
 	ss := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{Name: mesh.Name, Namespace: mesh.Namespace},
 	}
-	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, ss, func() error {
+	// Suspended and finished meshes keep their StatefulSet but run no workers.
+	replicas := workerCount(mesh)
+	if mesh.Spec.Suspend || isFinished(mesh) {
+		replicas = 0
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, ss, func() error {
 		ss.Labels = labels
-		ss.Spec.Replicas = &mesh.Spec.Replicas
+		ss.Spec.Replicas = &replicas
 		ss.Spec.ServiceName = svcName
 		ss.Spec.Selector = &metav1.LabelSelector{MatchLabels: selectorLabels}
 		// Use Parallel pod management to launch all pods simultaneously rather than sequentially.
 		// This can speed up large worker pod launches.
 		// See: https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/#parallel-pod-management
 		ss.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+		// The controller applies pod template changes itself according to Spec.UpdateStrategy,
+		// since a rolling update would mix incompatible Monarch versions in one mesh.
+		ss.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+		// Volume claim templates of a StatefulSet cannot be changed after creation.
+		if ss.CreationTimestamp.IsZero() {
+			ss.Spec.VolumeClaimTemplates = nil
+			for _, template := range mesh.Spec.VolumeClaimTemplates {
+				ss.Spec.VolumeClaimTemplates = append(ss.Spec.VolumeClaimTemplates, *template.DeepCopy())
+			}
+		}
+		ss.Spec.PersistentVolumeClaimRetentionPolicy = claimRetentionPolicy(mesh)
 		ss.Spec.Template.Labels = selectorLabels
-		ss.Spec.Template.Spec = mesh.Spec.PodTemplate
-		return ctrl.SetControllerReference(&mesh, ss, r.Scheme)
+		if err := setNetworksAnnotation(&ss.Spec.Template, mesh); err != nil {
+			return err
+		}
+		ss.Spec.Template.Spec = r.workerPodSpec(mesh, svcName, port)
+		return ctrl.SetControllerReference(mesh, ss, r.Scheme)
 	})
-	if err != nil {
-		log.Error(err, "Failed to create or update StatefulSet")
-		return ctrl.Result{}, err
+	return ss, err
+}
+
+// reconcileRanks reports the pod, node, addresses and volumes backing each rank in the status,
+// and publishes them in the hostfile.
+func (r *MonarchMeshReconciler) reconcileRanks(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+	svcName string, port int32,
+) error {
+	log := logf.FromContext(ctx)
+
+	// Promote spares into failed ranks and report the pod backing each rank.
+	if err := r.reconcileSpares(ctx, mesh, selectorLabels); err != nil {
+		log.Error(err, "Failed to reconcile spares")
+		return err
 	}
 
-	// 5. Update MonarchMesh status with observed state from StatefulSet.
-	// Status updates are triggered automatically when owned StatefulSet changes (via Owns()).
+	// Report the node and topology domain of each rank.
+	if err := r.reconcileTopology(ctx, mesh); err != nil {
+		log.Error(err, "Failed to report rank topology")
+		return err
+	}
+
+	// Report ranks on unhealthy nodes, and replace them according to Spec.UnhealthyNodePolicy.
+	if err := r.reconcileNodeHealth(ctx, mesh, selectorLabels); err != nil {
+		log.Error(err, "Failed to reconcile node health")
+		return err
+	}
+
+	// Report the DNS name, pod IP and secondary network addresses of each rank.
+	if err := r.reconcileRankAddresses(ctx, mesh, svcName); err != nil {
+		log.Error(err, "Failed to report rank addresses")
+		return err
+	}
+
+	// Report the claims of each rank created from Spec.VolumeClaimTemplates.
+	if err := r.reconcileRankVolumes(ctx, mesh); err != nil {
+		log.Error(err, "Failed to report rank volumes")
+		return err
+	}
+
+	// Publish the rank addresses in the hostfile ConfigMap.
+	if err := r.reconcileHostfile(ctx, mesh, selectorLabels, svcName, port); err != nil {
+		log.Error(err, "Failed to reconcile hostfile ConfigMap")
+		return err
+	}
+	return nil
+}
+
+// updateStatus computes the replicas, revisions, phase and Ready condition of the mesh from the
+// observed state of its StatefulSet.
+func updateStatus(mesh *monarchv1alpha1.MonarchMesh, ss *appsv1.StatefulSet) {
 	mesh.Status.Replicas = ss.Status.Replicas
 	mesh.Status.ReadyReplicas = ss.Status.ReadyReplicas
+	updateRevisions(mesh, ss)
 
-	condition := metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Waiting"}
-	if ss.Status.ReadyReplicas == mesh.Spec.Replicas {
-		condition = metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "AllReady"}
+	condition := metav1.Condition{
+		Type: monarchv1alpha1.MeshConditionReady, Status: metav1.ConditionFalse, Reason: "Waiting",
+	}
+	switch {
+	case isFinished(mesh):
+		// Terminal phases are never left.
+		condition.Reason = string(mesh.Status.Phase)
+	case mesh.Spec.Suspend:
+		mesh.Status.Phase = monarchv1alpha1.MonarchMeshSuspended
+		condition.Reason = "Suspended"
+	case ss.Status.ReadyReplicas >= mesh.Spec.Replicas:
+		mesh.Status.Phase = monarchv1alpha1.MonarchMeshRunning
+		condition = metav1.Condition{
+			Type: monarchv1alpha1.MeshConditionReady, Status: metav1.ConditionTrue, Reason: "AllReady",
+		}
+	default:
+		mesh.Status.Phase = monarchv1alpha1.MonarchMeshPending
 	}
 	meta.SetStatusCondition(&mesh.Status.Conditions, condition)
+}
 
-	if err := r.Status().Update(ctx, &mesh); err != nil {
-		log.Error(err, "Failed to update MonarchMesh status")
-		return ctrl.Result{}, err
+// reconcileWorkerHealth acts on the observed state of the workers: it updates outdated workers,
+// handles workers stuck starting, checkpoints and completes the mesh, and guards the workers
+// against disruptions. It returns the time until the workers must be checked again.
+func (r *MonarchMeshReconciler) reconcileWorkerHealth(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, ss *appsv1.StatefulSet,
+	selectorLabels map[string]string, svcName string, port int32,
+) (time.Duration, error) {
+	log := logf.FromContext(ctx)
+
+	// Recreate outdated workers according to Spec.UpdateStrategy.
+	if err := r.reconcileUpdate(ctx, mesh, ss, selectorLabels); err != nil {
+		log.Error(err, "Failed to update workers")
+		return 0, err
+	}
+
+	// Classify workers stuck starting past Spec.StartupTimeout and apply the configured action.
+	startupIn, err := r.reconcileStartupTimeout(ctx, mesh)
+	if err != nil {
+		log.Error(err, "Failed to reconcile startup timeout")
+		return 0, err
+	}
+
+	// Report the progress of the workers waiting in the startup barrier.
+	if err := r.reconcileStartupBarrier(ctx, mesh, selectorLabels); err != nil {
+		log.Error(err, "Failed to report startup barrier progress")
+		return 0, err
+	}
+
+	// Check the versions reported by the workers against Spec.MonarchVersion.
+	if err := r.reconcileVersionSkew(ctx, mesh, selectorLabels); err != nil {
+		log.Error(err, "Failed to check worker versions")
+		return 0, err
 	}
 
-	return ctrl.Result{}, nil
+	// Ask the workers to checkpoint when they are about to be disrupted.
+	released, checkpointIn, err := r.reconcileCheckpoint(ctx, mesh)
+	if err != nil {
+		log.Error(err, "Failed to reconcile checkpoint requests")
+		return 0, err
+	}
+
+	// Run the client once all workers are ready, and finish the mesh when it exits.
+	if err := r.reconcileClient(ctx, mesh, svcName, port); err != nil {
+		log.Error(err, "Failed to reconcile client Job")
+		return 0, err
+	}
+
+	// Finish the mesh according to Spec.CompletionPolicy.
+	if err := r.reconcileCompletion(ctx, mesh, selectorLabels); err != nil {
+		log.Error(err, "Failed to reconcile completion")
+		return 0, err
+	}
+
+	// Stop the client of meshes that finished or were suspended, and resume it once they run.
+	if err := r.reconcileClientSuspension(ctx, mesh); err != nil {
+		log.Error(err, "Failed to suspend client Job")
+		return 0, err
+	}
+
+	// Ensure the PodDisruptionBudget matches the disruption policy for the current phase.
+	if err := r.reconcilePodDisruptionBudget(ctx, mesh, selectorLabels, released); err != nil {
+		log.Error(err, "Failed to reconcile PodDisruptionBudget")
+		return 0, err
+	}
+	return earliest(checkpointIn, startupIn), nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MonarchMeshReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, podNodeNameField, indexPodNodeName)
+	if err != nil {
+		return err
+	}
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &monarchv1alpha1.MonarchMesh{},
+		templateRefField, indexTemplateRef)
+	if err != nil {
+		return err
+	}
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &monarchv1alpha1.MonarchMesh{},
+		classNameField, indexClassName)
+	if err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&monarchv1alpha1.MonarchMesh{}).
 		// Owns() watches StatefulSets that have an OwnerReference pointing to a MonarchMesh.
@@ -178,9 +604,105 @@ func (r *MonarchMeshReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		// MonarchMesh.Status (Replicas, ReadyReplicas, Conditions).
 		// See: https://book.kubebuilder.io/reference/watching-resources/owned
 		Owns(&appsv1.StatefulSet{}).
+		// PodDisruptionBudget status changes are reflected in the DisruptionAllowed condition.
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		// Client Job completion or failure finishes the mesh.
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.ConfigMap{}).
+		// Worker pods are not owned by the mesh but by its StatefulSet. Changes to the version
+		// they report are mapped back to the mesh through the mesh label.
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.meshForLabeledObject)).
+		// Per-rank claims are created by the StatefulSet controller, which copies the selector
+		// labels, including the mesh label, onto them.
+		Watches(&corev1.PersistentVolumeClaim{}, handler.EnqueueRequestsFromMapFunc(r.meshForLabeledObject)).
+		// Taints and conditions of the nodes hosting workers are mapped to their meshes.
+		Watches(&corev1.Node{}, handler.EnqueueRequestsFromMapFunc(r.meshesForNode),
+			builder.WithPredicates(nodeHealthChanged)).
+		// Template changes are resolved into the pod template of the referencing meshes, and
+		// reach their workers according to Spec.UpdateStrategy.
+		Watches(&monarchv1alpha1.MonarchMeshTemplate{}, handler.EnqueueRequestsFromMapFunc(r.meshesForTemplate)).
+		Watches(&monarchv1alpha1.ClusterMonarchMeshTemplate{}, handler.EnqueueRequestsFromMapFunc(r.meshesForTemplate)).
+		// Class changes are applied to the meshes of the class, and changes to the default and
+		// allowed classes of a namespace to the meshes in it.
+		Watches(&monarchv1alpha1.MonarchMeshClass{}, handler.EnqueueRequestsFromMapFunc(r.meshesForClass)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.meshesInNamespace),
+			builder.WithPredicates(classPolicyChanged)).
+		// Queued meshes are admitted once a quota of their namespace is raised or its usage,
+		// reported by the quota controller, drops.
+		Watches(&monarchv1alpha1.MonarchMeshQuota{}, handler.EnqueueRequestsFromMapFunc(r.queuedMeshesForQuota)).
 		Complete(r)
 }
 
+// earliest returns the shortest of the positive durations, or zero if there is none.
+func earliest(durations ...time.Duration) time.Duration {
+	var result time.Duration
+	for _, d := range durations {
+		if d > 0 && (result == 0 || d < result) {
+			result = d
+		}
+	}
+	return result
+}
+
+// recordEvent records an event for the mesh if the reconciler has an event recorder.
+func (r *MonarchMeshReconciler) recordEvent(mesh *monarchv1alpha1.MonarchMesh, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(mesh, eventType, reason, message)
+	}
+}
+
+// workerPodSpec returns the pod spec for mesh workers: the user-provided PodTemplate with
+// the operator-managed volumes and environment applied. The MonarchMesh spec is not modified.
+func (r *MonarchMeshReconciler) workerPodSpec(
+	mesh *monarchv1alpha1.MonarchMesh, svcName string, port int32,
+) corev1.PodSpec {
+	spec := mesh.Spec.PodTemplate.DeepCopy()
+	if mesh.Spec.RBAC != nil && mesh.Spec.RBAC.CreateWorkerServiceAccount && spec.ServiceAccountName == "" {
+		spec.ServiceAccountName = workerServiceAccountName(mesh)
+	}
+	if mesh.Spec.Topology != nil {
+		r.injectTopology(spec, mesh)
+	}
+	if len(mesh.Spec.Networks) > 0 {
+		injectNetworks(spec, mesh)
+	}
+	if mesh.Spec.Hostfile != nil && mesh.Spec.Hostfile.MountPath != "" {
+		r.injectHostfile(spec, mesh)
+	}
+	if mesh.Spec.SharedStorage != nil {
+		r.injectSharedStorage(spec, mesh)
+	}
+	if mesh.Spec.StartupBarrier != nil {
+		r.injectStartupBarrier(spec, mesh, svcName, port)
+	}
+	if mesh.Spec.TLS != nil {
+		r.injectWorkerTLS(spec, mesh)
+	}
+	if mesh.Spec.UnhealthyNodePolicy == monarchv1alpha1.UnhealthyNodeReplace {
+		injectNodeHealthGate(spec)
+	}
+	return *spec
+}
+
+// meshPort returns the port of the mesh, defaulting to Config.DefaultPort when not specified in
+// the spec.
+func (r *MonarchMeshReconciler) meshPort(mesh *monarchv1alpha1.MonarchMesh) int32 {
+	if mesh.Spec.Port != 0 {
+		return mesh.Spec.Port
+	}
+	return r.Config.DefaultPort
+}
+
+// withWorkerPodSpec returns a copy of mesh whose pod template is the pod spec of its workers,
+// including what the operator injects, e.g. the startup barrier and the TLS sidecar, so that
+// MonarchMeshQuotas and the admission queue count what the workers actually request.
+func (r *MonarchMeshReconciler) withWorkerPodSpec(mesh *monarchv1alpha1.MonarchMesh) *monarchv1alpha1.MonarchMesh {
+	workers := mesh.DeepCopy()
+	workers.Spec.PodTemplate = r.workerPodSpec(mesh, mesh.Name+r.Config.ServiceSuffix, r.meshPort(mesh))
+	return workers
+}
+
 // mergeLabels merges base labels with override labels.
 // Override labels take precedence when the same key exists in both maps.
 // Returns a new map without modifying the input maps.
@@ -197,3 +719,20 @@ func mergeLabels(base, override map[string]string, log logr.Logger) map[string]s
 	}
 	return result
 }
+
+// deleteOwned deletes obj if it exists and is controlled by the mesh.
+// Objects with the same name that were not created by the controller are left untouched.
+func (r *MonarchMeshReconciler) deleteOwned(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, obj client.Object, opts ...client.DeleteOption,
+) error {
+	if err := r.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, mesh) {
+		return nil
+	}
+	if err := r.Delete(ctx, obj, opts...); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
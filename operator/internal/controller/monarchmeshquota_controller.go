@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshquota"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshtemplate"
+)
+
+// MonarchMeshQuotaReconciler reports the usage of a MonarchMeshQuota. The quota itself is
+// enforced by the validating webhook and the MonarchMesh reconciler.
+type MonarchMeshQuotaReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// RBAC permissions for the quota controller. Mesh access is granted to the MonarchMesh controller.
+//
+// monarchmeshquotas, monarchmeshquotas/status (get;list;watch, get;update;patch):
+//   The controller reports the meshes, workers and resources counting against each quota, and
+//   the number of meshes queued in its namespace.
+
+// +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshquotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monarch.pytorch.org,resources=monarchmeshquotas/status,verbs=get;update;patch
+
+// Reconcile computes the usage of the quota from the meshes of its namespace.
+func (r *MonarchMeshQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var quota monarchv1alpha1.MonarchMeshQuota
+	if err := r.Get(ctx, req.NamespacedName, &quota); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	if err := r.List(ctx, meshes, client.InNamespace(quota.Namespace)); err != nil {
+		log.Error(err, "Failed to list MonarchMeshes")
+		return ctrl.Result{}, err
+	}
+	// Workers taken from the template of Spec.TemplateRef count with the requests of the template.
+	if err := meshtemplate.ResolveAll(ctx, r.Client, meshes.Items); err != nil {
+		log.Error(err, "Failed to resolve mesh templates")
+		return ctrl.Result{}, err
+	}
+
+	status := monarchv1alpha1.MonarchMeshQuotaStatus{
+		Used: meshquota.Limited(&quota, meshquota.Used(meshes.Items, "")),
+	}
+	for _, mesh := range meshes.Items {
+		if mesh.Status.Phase == monarchv1alpha1.MonarchMeshQueued {
+			status.Queued++
+		}
+	}
+	if apiequality.Semantic.DeepEqual(quota.Status, status) {
+		return ctrl.Result{}, nil
+	}
+	quota.Status = status
+	if err := r.Status().Update(ctx, &quota); err != nil {
+		log.Error(err, "Failed to update MonarchMeshQuota status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MonarchMeshQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monarchv1alpha1.MonarchMeshQuota{}).
+		// Every change to a mesh may change the usage of the quotas of its namespace.
+		Watches(&monarchv1alpha1.MonarchMesh{}, handler.EnqueueRequestsFromMapFunc(r.quotasForMesh)).
+		Complete(r)
+}
+
+// quotasForMesh maps a MonarchMesh to the quotas of its namespace.
+func (r *MonarchMeshQuotaReconciler) quotasForMesh(ctx context.Context, obj client.Object) []reconcile.Request {
+	quotas := &monarchv1alpha1.MonarchMeshQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(obj.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list quotas for mesh", "mesh", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(quotas.Items))
+	for _, quota := range quotas.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: quota.Name, Namespace: quota.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// reconcileNetworkPolicy creates or updates the NetworkPolicy isolating the mesh workers when
+// Spec.NetworkIsolation is set, and removes it otherwise.
+//
+// The policy selects the mesh pods and admits ingress on the mesh port from pods of the same mesh
+// (selected by selectorLabels), from the client run for Spec.Client, and from the configured
+// client pods. Because the policy has the Ingress policy type, all other ingress to the workers
+// is denied. Egress is not affected.
+func (r *MonarchMeshReconciler) reconcileNetworkPolicy(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string, port int32,
+) error {
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: mesh.Name, Namespace: mesh.Namespace},
+	}
+
+	isolation := mesh.Spec.NetworkIsolation
+	if isolation == nil {
+		return r.deleteOwned(ctx, mesh, np)
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, np, func() error {
+		np.Labels = selectorLabels
+		np.Spec.PodSelector = metav1.LabelSelector{MatchLabels: selectorLabels}
+		np.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+
+		peers := []networkingv1.NetworkPolicyPeer{{
+			PodSelector: &metav1.LabelSelector{MatchLabels: selectorLabels},
+		}}
+		if mesh.Spec.Client != nil {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				PodSelector: &metav1.LabelSelector{MatchLabels: r.clientLabels(mesh)},
+			})
+		}
+		if isolation.ClientPodSelector != nil {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				PodSelector:       isolation.ClientPodSelector,
+				NamespaceSelector: isolation.ClientNamespaceSelector,
+			})
+		}
+
+		protocol := corev1.ProtocolTCP
+		meshPort := intstr.FromInt32(port)
+		np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{
+			From:  peers,
+			Ports: []networkingv1.NetworkPolicyPort{{Protocol: &protocol, Port: &meshPort}},
+		}}
+		return ctrl.SetControllerReference(mesh, np, r.Scheme)
+	})
+	return err
+}
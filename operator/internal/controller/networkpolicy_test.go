@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh NetworkPolicy", func() {
+	const resourceName = "netpol-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{},
+			&networkingv1.NetworkPolicy{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+	})
+
+	newMesh := func(isolation *monarchv1alpha1.NetworkIsolation) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.Port = 12345
+		mesh.Spec.NetworkIsolation = isolation
+		return mesh
+	}
+
+	It("should not create a NetworkPolicy by default", func() {
+		Expect(k8sClient.Create(ctx, newMesh(nil))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		err := k8sClient.Get(ctx, typeNamespacedName, &networkingv1.NetworkPolicy{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should admit only mesh peers and selected clients on the mesh port", func() {
+		clientSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"role": "monarch-client"}}
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.NetworkIsolation{
+			ClientPodSelector: clientSelector,
+		}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		np := &networkingv1.NetworkPolicy{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, np)).To(Succeed())
+		Expect(np.Spec.PodSelector.MatchLabels).To(HaveKeyWithValue(reconciler.Config.MeshLabelKey, resourceName))
+		Expect(np.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+		Expect(np.OwnerReferences).To(HaveLen(1))
+
+		Expect(np.Spec.Ingress).To(HaveLen(1))
+		rule := np.Spec.Ingress[0]
+		Expect(rule.Ports).To(HaveLen(1))
+		Expect(rule.Ports[0].Port.IntValue()).To(Equal(12345))
+		Expect(rule.From).To(HaveLen(2))
+		Expect(rule.From[0].PodSelector.MatchLabels).To(HaveKeyWithValue(reconciler.Config.MeshLabelKey, resourceName))
+		Expect(rule.From[1].PodSelector).To(Equal(clientSelector))
+		Expect(rule.From[1].NamespaceSelector).To(BeNil())
+	})
+
+	It("should remove the NetworkPolicy when isolation is disabled", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.NetworkIsolation{}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(k8sClient.Get(ctx, typeNamespacedName, &networkingv1.NetworkPolicy{})).To(Succeed())
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.NetworkIsolation = nil
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		err := k8sClient.Get(ctx, typeNamespacedName, &networkingv1.NetworkPolicy{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// Multus annotations requesting secondary networks and reporting their status.
+// See https://github.com/k8snetworkplumbingwg/multi-net-spec
+const (
+	networksAnnotation      = "k8s.v1.cni.cncf.io/networks"
+	networkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+)
+
+// envMeshInterface names the interface of the first secondary network in the worker containers.
+const envMeshInterface = "MONARCH_MESH_INTERFACE"
+
+// networkSelection is an entry of the Multus networks annotation.
+type networkSelection struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// networkStatus is an entry of the Multus network-status annotation.
+type networkStatus struct {
+	Name      string   `json:"name"`
+	Interface string   `json:"interface,omitempty"`
+	IPs       []string `json:"ips,omitempty"`
+	Default   bool     `json:"default,omitempty"`
+}
+
+// networkInterface returns the interface name of the i-th network of Spec.Networks.
+func networkInterface(network monarchv1alpha1.MeshNetwork, i int) string {
+	if network.Interface != "" {
+		return network.Interface
+	}
+	return fmt.Sprintf("net%d", i+1)
+}
+
+// networksAnnotationValue returns the Multus networks annotation for Spec.Networks.
+func networksAnnotationValue(mesh *monarchv1alpha1.MonarchMesh) (string, error) {
+	selections := make([]networkSelection, 0, len(mesh.Spec.Networks))
+	for i, network := range mesh.Spec.Networks {
+		namespace := network.Namespace
+		if namespace == "" {
+			namespace = mesh.Namespace
+		}
+		selections = append(selections, networkSelection{
+			Name: network.Name, Namespace: namespace, Interface: networkInterface(network, i),
+		})
+	}
+	value, err := json.Marshal(selections)
+	return string(value), err
+}
+
+// setNetworksAnnotation requests Spec.Networks on the worker pod template. Other annotations of
+// the template, e.g. set by kubectl rollout restart, are left untouched.
+func setNetworksAnnotation(template *corev1.PodTemplateSpec, mesh *monarchv1alpha1.MonarchMesh) error {
+	if len(mesh.Spec.Networks) == 0 {
+		delete(template.Annotations, networksAnnotation)
+		return nil
+	}
+	value, err := networksAnnotationValue(mesh)
+	if err != nil {
+		return err
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[networksAnnotation] = value
+	return nil
+}
+
+// injectNetworks passes the interface of the first secondary network to the worker containers.
+func injectNetworks(spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh) {
+	env := corev1.EnvVar{Name: envMeshInterface, Value: networkInterface(mesh.Spec.Networks[0], 0)}
+	for i := range spec.Containers {
+		spec.Containers[i].Env = append(spec.Containers[i].Env, env)
+	}
+}
+
+// reconcileRankAddresses reports the DNS name and pod IP of each rank and, with Spec.Networks,
+// its addresses on the secondary networks read from the Multus network-status annotation.
+func (r *MonarchMeshReconciler) reconcileRankAddresses(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, svcName string,
+) error {
+	for i := range mesh.Status.Ranks {
+		rank := &mesh.Status.Ranks[i]
+		rank.DNSName = podDNSName(rank.Pod, svcName, mesh.Namespace)
+		rank.IP, rank.Networks = "", nil
+		pod := &corev1.Pod{}
+		err := r.Get(ctx, types.NamespacedName{Name: rank.Pod, Namespace: mesh.Namespace}, pod)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rank.IP = pod.Status.PodIP
+		if len(mesh.Spec.Networks) == 0 {
+			continue
+		}
+		value, ok := pod.Annotations[networkStatusAnnotation]
+		if !ok {
+			continue
+		}
+		var statuses []networkStatus
+		if err := json.Unmarshal([]byte(value), &statuses); err != nil {
+			logf.FromContext(ctx).Info("Ignoring malformed network-status annotation", "pod", pod.Name)
+			continue
+		}
+		for _, status := range statuses {
+			if status.Default {
+				continue
+			}
+			rank.Networks = append(rank.Networks, monarchv1alpha1.RankNetwork{
+				Name: status.Name, Interface: status.Interface, IPs: status.IPs,
+			})
+		}
+	}
+	return nil
+}
+
+// fastPathAddresses returns the first address of each rank on the first secondary network, in
+// rank order, or nil if any rank has no such address yet.
+func fastPathAddresses(mesh *monarchv1alpha1.MonarchMesh) []string {
+	if len(mesh.Spec.Networks) == 0 || len(mesh.Status.Ranks) == 0 {
+		return nil
+	}
+	iface := networkInterface(mesh.Spec.Networks[0], 0)
+	addresses := make([]string, len(mesh.Status.Ranks))
+	for _, rank := range mesh.Status.Ranks {
+		for _, network := range rank.Networks {
+			if network.Interface == iface && len(network.IPs) > 0 {
+				addresses[rank.Rank] = network.IPs[0]
+			}
+		}
+		if addresses[rank.Rank] == "" {
+			return nil
+		}
+	}
+	return addresses
+}
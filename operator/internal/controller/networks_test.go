@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh secondary networks", func() {
+	const resourceName = "networks-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, types.NamespacedName{Name: resourceName + "-0", Namespace: "default"}, &corev1.Pod{})
+	})
+
+	It("should attach the networks and report the secondary addresses of each rank", func() {
+		mesh := newTestMesh(typeNamespacedName, 1)
+		mesh.Spec.Networks = []monarchv1alpha1.MeshNetwork{{Name: "rdma"}, {Name: "ib", Namespace: "fabric"}}
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Annotations).To(HaveKeyWithValue(networksAnnotation,
+			`[{"name":"rdma","namespace":"default","interface":"net1"},`+
+				`{"name":"ib","namespace":"fabric","interface":"net2"}]`))
+		Expect(ss.Spec.Template.Spec.Containers[0].Env).To(
+			ContainElement(corev1.EnvVar{Name: envMeshInterface, Value: "net1"}))
+
+		By("Reading the network-status annotation published by Multus")
+		Expect(k8sClient.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resourceName + "-0", Namespace: "default",
+				Annotations: map[string]string{networkStatusAnnotation: `[
+					{"name":"cbr0","interface":"eth0","ips":["10.0.0.5"],"default":true},
+					{"name":"default/rdma","interface":"net1","ips":["192.168.1.5"]}]`},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}}},
+		})).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Ranks).To(HaveLen(1))
+		rank := mesh.Status.Ranks[0]
+		Expect(rank.DNSName).To(Equal(resourceName + "-0." + resourceName + "-svc.default.svc"))
+		Expect(rank.Networks).To(Equal([]monarchv1alpha1.RankNetwork{
+			{Name: "default/rdma", Interface: "net1", IPs: []string{"192.168.1.5"}},
+		}))
+		Expect(fastPathAddresses(mesh)).To(Equal([]string{"192.168.1.5"}))
+	})
+})
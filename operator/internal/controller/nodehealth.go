@@ -0,0 +1,248 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+const (
+	// nodeNotReady is the problem of a node whose Ready condition is not True.
+	nodeNotReady = "NotReady"
+
+	// nodeDeleted is the problem of a rank whose node no longer exists.
+	nodeDeleted = "NodeDeleted"
+
+	// nodeHealthSchedulingGate holds workers of meshes with the Replace unhealthy node policy
+	// back from scheduling until they are kept off unhealthy nodes.
+	nodeHealthSchedulingGate = "monarch.pytorch.org/node-health"
+)
+
+// nodeProblems returns the problems making the node unhealthy: a Ready condition that is not
+// True, a condition of Config.UnhealthyNodeConditions that is True, or a taint of
+// Config.UnhealthyNodeTaintKeys.
+func nodeProblems(config *Config, node *corev1.Node) []string {
+	var problems []string
+	for _, condition := range node.Status.Conditions {
+		switch {
+		case condition.Type == corev1.NodeReady && condition.Status != corev1.ConditionTrue:
+			problems = append(problems, nodeNotReady)
+		case slices.Contains(config.UnhealthyNodeConditions, string(condition.Type)) &&
+			condition.Status == corev1.ConditionTrue:
+			problems = append(problems, string(condition.Type))
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if slices.Contains(config.UnhealthyNodeTaintKeys, taint.Key) {
+			problems = append(problems, taint.Key)
+		}
+	}
+	return problems
+}
+
+// reconcileNodeHealth reports the ranks on unhealthy nodes in the NodeUnhealthy condition and,
+// with the Replace policy, deletes the workers on them. The replacement workers are kept off
+// unhealthy nodes by releaseGatedWorkers.
+func (r *MonarchMeshReconciler) reconcileNodeHealth(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) error {
+	var affected []string
+	for _, rank := range mesh.Status.Ranks {
+		if rank.Node == "" {
+			continue
+		}
+		node := &corev1.Node{}
+		var problems []string
+		err := r.Get(ctx, types.NamespacedName{Name: rank.Node}, node)
+		switch {
+		case apierrors.IsNotFound(err):
+			node = nil
+			problems = []string{nodeDeleted}
+		case err != nil:
+			return err
+		default:
+			problems = nodeProblems(&r.Config, node)
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		affected = append(affected,
+			fmt.Sprintf("rank %d on node %s (%s)", rank.Rank, rank.Node, strings.Join(problems, ", ")))
+		if mesh.Spec.UnhealthyNodePolicy == monarchv1alpha1.UnhealthyNodeReplace {
+			if err := r.replaceRank(ctx, mesh, rank, node); err != nil {
+				return err
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionNodeUnhealthy,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NodesHealthy",
+		Message: "All ranks run on healthy nodes",
+	}
+	if len(affected) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NodesUnhealthy"
+		condition.Message = "Ranks on unhealthy nodes: " + strings.Join(affected, "; ")
+		if !meta.IsStatusConditionTrue(mesh.Status.Conditions, monarchv1alpha1.MeshConditionNodeUnhealthy) {
+			r.recordEvent(mesh, corev1.EventTypeWarning, "NodeUnhealthy", condition.Message)
+		}
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, condition)
+	return r.releaseGatedWorkers(ctx, mesh, selectorLabels)
+}
+
+// replaceRank deletes the worker backing a rank on an unhealthy node. node is nil when the node
+// was deleted.
+//
+// Workers are deleted gracefully: a NotReady node may only be cut off from the network with the
+// worker still running, and deleting it without grace period would let the StatefulSet start a
+// second copy of the rank elsewhere. Only once the node is known to be gone, i.e. deleted or
+// tainted out-of-service, are its workers deleted without grace period, since their kubelet
+// cannot confirm the deletion and they would otherwise stay Terminating, holding on to their rank.
+func (r *MonarchMeshReconciler) replaceRank(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, rank monarchv1alpha1.RankStatus, node *corev1.Node,
+) error {
+	pod := &corev1.Pod{}
+	err := r.Get(ctx, types.NamespacedName{Name: rank.Pod, Namespace: mesh.Namespace}, pod)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	force := node == nil || nodeOutOfService(node)
+	if pod.DeletionTimestamp != nil && (!force || ptr.Deref(pod.DeletionGracePeriodSeconds, 0) == 0) {
+		return nil
+	}
+	opts := []client.DeleteOption{client.Preconditions{UID: &pod.UID}}
+	if force {
+		opts = append(opts, client.GracePeriodSeconds(0))
+	}
+	if err := r.Delete(ctx, pod, opts...); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	r.recordEvent(mesh, corev1.EventTypeWarning, "RankReplaced",
+		fmt.Sprintf("Deleted worker %s of rank %d on unhealthy node %s", pod.Name, rank.Rank, rank.Node))
+	return nil
+}
+
+// nodeOutOfService returns whether the node carries the out-of-service taint, set by cluster
+// admins once a node is confirmed to be shut down.
+func nodeOutOfService(node *corev1.Node) bool {
+	return slices.ContainsFunc(node.Spec.Taints, func(taint corev1.Taint) bool {
+		return taint.Key == corev1.TaintNodeOutOfService
+	})
+}
+
+// injectNodeHealthGate holds the workers of meshes with the Replace policy back from scheduling
+// until releaseGatedWorkers kept them off unhealthy nodes. The gate is the same for every
+// worker, so the pod template doesn't change when nodes become unhealthy, which would recreate
+// every worker under the Recreate update strategy.
+func injectNodeHealthGate(spec *corev1.PodSpec) {
+	spec.SchedulingGates = append(spec.SchedulingGates, corev1.PodSchedulingGate{Name: nodeHealthSchedulingGate})
+}
+
+// releaseGatedWorkers adds a required node affinity keeping each worker held back by the node
+// health gate off the unhealthy nodes, and removes the gate. The node affinity of a pod may only
+// be narrowed while it is gated. The affinity only applies to that pod, so the workers of other
+// meshes and other workloads still run on these nodes.
+func (r *MonarchMeshReconciler) releaseGatedWorkers(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) error {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(mesh.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return err
+	}
+	gated := func(gate corev1.PodSchedulingGate) bool { return gate.Name == nodeHealthSchedulingGate }
+	var unhealthy []string
+	listed := false
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !slices.ContainsFunc(pod.Spec.SchedulingGates, gated) {
+			continue
+		}
+		if !listed {
+			hosts, err := r.unhealthyHosts(ctx)
+			if err != nil {
+				return err
+			}
+			unhealthy, listed = hosts, true
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		pod.Spec.SchedulingGates = slices.DeleteFunc(pod.Spec.SchedulingGates, gated)
+		if len(unhealthy) > 0 {
+			avoidHosts(&pod.Spec, unhealthy)
+		}
+		if err := r.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unhealthyHosts returns the hostname labels of the unhealthy nodes of the cluster.
+func (r *MonarchMeshReconciler) unhealthyHosts(ctx context.Context) ([]string, error) {
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	var hosts []string
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if len(nodeProblems(&r.Config, node)) == 0 {
+			continue
+		}
+		host := node.Labels[corev1.LabelHostname]
+		if host == "" {
+			host = node.Name
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// avoidHosts requires the pod to run on none of the hosts. A gated pod may only gain
+// requirements, so the requirement is added to each existing node selector term.
+func avoidHosts(spec *corev1.PodSpec, hosts []string) {
+	requirement := corev1.NodeSelectorRequirement{
+		Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpNotIn, Values: hosts,
+	}
+	if spec.Affinity == nil {
+		spec.Affinity = &corev1.Affinity{}
+	}
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	required := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{requirement}}},
+		}
+		return
+	}
+	for i := range required.NodeSelectorTerms {
+		term := &required.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh node health", func() {
+	const (
+		resourceName = "node-health-test-mesh"
+		nodeName     = "node-health-test-node"
+	)
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		podNamespacedName  types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		podNamespacedName = types.NamespacedName{Name: resourceName + "-0", Namespace: "default"}
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		Expect(k8sClient.Create(ctx, node)).To(Succeed())
+		node.Status.Conditions = []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+		}
+		Expect(k8sClient.Status().Update(ctx, node)).To(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podNamespacedName.Name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:   nodeName,
+				Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}},
+			},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		// Workers deleted gracefully stay Terminating without a kubelet.
+		pod := &corev1.Pod{}
+		if err := k8sClient.Get(ctx, podNamespacedName, pod); err == nil {
+			Expect(k8sClient.Delete(ctx, pod, client.GracePeriodSeconds(0))).To(Succeed())
+		}
+		deleteIfExists(ctx, types.NamespacedName{Name: nodeName}, &corev1.Node{})
+	})
+
+	createMesh := func(policy monarchv1alpha1.UnhealthyNodePolicy) {
+		mesh := newTestMesh(typeNamespacedName, 1)
+		mesh.Spec.UnhealthyNodePolicy = policy
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+	}
+
+	It("should name the ranks on unhealthy nodes in the NodeUnhealthy condition", func() {
+		createMesh(monarchv1alpha1.UnhealthyNodeReport)
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionNodeUnhealthy)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("rank 0 on node " + nodeName + " (DiskPressure)"))
+
+		node := &corev1.Node{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: nodeName}, node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(k8sClient.Get(ctx, podNamespacedName, &corev1.Pod{})).To(Succeed())
+	})
+
+	It("should delete the worker with the Replace policy without cordoning the node", func() {
+		createMesh(monarchv1alpha1.UnhealthyNodeReplace)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		node := &corev1.Node{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: nodeName}, node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		pod := &corev1.Pod{}
+		err := k8sClient.Get(ctx, podNamespacedName, pod)
+		Expect(apierrors.IsNotFound(err) || pod.DeletionTimestamp != nil).To(BeTrue())
+	})
+
+	setNodeReady := func(status corev1.ConditionStatus) {
+		node := &corev1.Node{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: nodeName}, node)).To(Succeed())
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}
+		Expect(k8sClient.Status().Update(ctx, node)).To(Succeed())
+	}
+
+	It("should delete the worker on a NotReady node gracefully", func() {
+		setNodeReady(corev1.ConditionUnknown)
+		createMesh(monarchv1alpha1.UnhealthyNodeReplace)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		// The node may only be partitioned with the worker still running, so the worker must
+		// not be removed before its kubelet confirms the deletion.
+		pod := &corev1.Pod{}
+		Expect(k8sClient.Get(ctx, podNamespacedName, pod)).To(Succeed())
+		Expect(pod.DeletionTimestamp).NotTo(BeNil())
+		Expect(pod.DeletionGracePeriodSeconds).NotTo(BeNil())
+		Expect(*pod.DeletionGracePeriodSeconds).To(BeNumerically(">", 0))
+
+		By("Reconciling again without force deleting the terminating worker")
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(k8sClient.Get(ctx, podNamespacedName, pod)).To(Succeed())
+		Expect(*pod.DeletionGracePeriodSeconds).To(BeNumerically(">", 0))
+	})
+
+	It("should force delete the worker on a node tainted out-of-service", func() {
+		setNodeReady(corev1.ConditionFalse)
+		node := &corev1.Node{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: nodeName}, node)).To(Succeed())
+		node.Spec.Taints = []corev1.Taint{{
+			Key: corev1.TaintNodeOutOfService, Value: "nodeshutdown", Effect: corev1.TaintEffectNoExecute,
+		}}
+		Expect(k8sClient.Update(ctx, node)).To(Succeed())
+		createMesh(monarchv1alpha1.UnhealthyNodeReplace)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, podNamespacedName, &corev1.Pod{}))).To(BeTrue())
+	})
+
+	It("should release new workers with a node affinity avoiding unhealthy nodes", func() {
+		createMesh(monarchv1alpha1.UnhealthyNodeReplace)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.SchedulingGates).To(ConsistOf(
+			corev1.PodSchedulingGate{Name: nodeHealthSchedulingGate}))
+
+		By("Creating a gated worker from the template, as the StatefulSet controller would")
+		replacement := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resourceName + "-1", Namespace: "default", Labels: ss.Spec.Template.Labels,
+			},
+			Spec: ss.Spec.Template.Spec,
+		}
+		Expect(k8sClient.Create(ctx, replacement)).To(Succeed())
+		DeferCleanup(k8sClient.Delete, ctx, replacement)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(replacement), replacement)).To(Succeed())
+		Expect(replacement.Spec.SchedulingGates).To(BeEmpty())
+		Expect(replacement.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.
+			NodeSelectorTerms).To(ConsistOf(corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{
+				Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpNotIn, Values: []string{nodeName},
+			}},
+		}))
+	})
+})
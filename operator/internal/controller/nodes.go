@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// podNodeNameField indexes pods by the node they are scheduled to.
+const podNodeNameField = "spec.nodeName"
+
+// indexPodNodeName returns the node of a pod for the podNodeNameField index.
+func indexPodNodeName(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+// meshesForNode maps a node to the MonarchMeshes with workers scheduled to it.
+func (r *MonarchMeshReconciler) meshesForNode(ctx context.Context, obj client.Object) []reconcile.Request {
+	pods := &corev1.PodList{}
+	err := r.List(ctx, pods,
+		client.MatchingFields{podNodeNameField: obj.GetName()},
+		client.MatchingLabels{r.Config.AppLabelKey: r.Config.AppLabelValue},
+	)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list workers on node", "node", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, pod := range pods.Items {
+		name, ok := pod.Labels[r.Config.MeshLabelKey]
+		if !ok {
+			continue
+		}
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: pod.Namespace}}
+		if !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// nodeHealthChanged filters node updates down to changes of taints, schedulability and
+// condition statuses, ignoring the periodic heartbeats of the kubelet.
+var nodeHealthChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNode, ok := e.ObjectOld.(*corev1.Node)
+		if !ok {
+			return true
+		}
+		newNode, ok := e.ObjectNew.(*corev1.Node)
+		if !ok {
+			return true
+		}
+		return !equality.Semantic.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+			oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+			!slices.Equal(nodeConditionStatuses(oldNode), nodeConditionStatuses(newNode))
+	},
+}
+
+// nodeConditionStatuses returns the type and status of each node condition.
+func nodeConditionStatuses(node *corev1.Node) []string {
+	statuses := make([]string, 0, len(node.Status.Conditions))
+	for _, condition := range node.Status.Conditions {
+		statuses = append(statuses, string(condition.Type)+"="+string(condition.Status))
+	}
+	return statuses
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// queuedMeshesForQuota maps a MonarchMeshQuota to the queued meshes of its namespace, so that
+// they are admitted once the quota is raised or its usage drops.
+func (r *MonarchMeshReconciler) queuedMeshesForQuota(ctx context.Context, obj client.Object) []reconcile.Request {
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	if err := r.List(ctx, meshes, client.InNamespace(obj.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list meshes for quota", "quota", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, mesh := range meshes.Items {
+		if mesh.Status.Phase == monarchv1alpha1.MonarchMeshQueued {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: mesh.Name, Namespace: mesh.Namespace},
+			})
+		}
+	}
+	return requests
+}
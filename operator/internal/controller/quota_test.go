@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh quotas", func() {
+	const (
+		resourceName = "quota-test-mesh"
+		otherName    = "quota-test-other"
+		gpu          = corev1.ResourceName("nvidia.com/gpu")
+	)
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		recorder           *record.FakeRecorder
+		typeNamespacedName types.NamespacedName
+		quotaName          types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: recorder,
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		quotaName = types.NamespacedName{Name: "quota-test", Namespace: "default"}
+		Expect(k8sClient.Create(ctx, &monarchv1alpha1.MonarchMeshQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: quotaName.Name, Namespace: quotaName.Namespace},
+			Spec: monarchv1alpha1.MonarchMeshQuotaSpec{
+				Resources:       corev1.ResourceList{gpu: resource.MustParse("16")},
+				OverQuotaAction: monarchv1alpha1.OverQuotaQueue,
+			},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, name := range []string{resourceName, otherName} {
+			key := types.NamespacedName{Name: name, Namespace: "default"}
+			deleteIfExists(ctx, key, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+			deleteIfExists(ctx, types.NamespacedName{
+				Name: name + reconciler.Config.ServiceSuffix, Namespace: "default",
+			}, &corev1.Service{})
+		}
+		deleteIfExists(ctx, quotaName, &monarchv1alpha1.MonarchMeshQuota{})
+	})
+
+	newMesh := func(name string, replicas int32) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(types.NamespacedName{Name: name, Namespace: "default"}, replicas)
+		mesh.Spec.PodTemplate.Containers[0].Resources.Limits = corev1.ResourceList{gpu: resource.MustParse("8")}
+		return mesh
+	}
+
+	It("should queue meshes over quota until the quota is raised", func() {
+		Expect(k8sClient.Create(ctx, newMesh(resourceName, 4))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionQueued)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("QuotaExceeded"))
+		Expect(condition.Message).To(ContainSubstring("nvidia.com/gpu: 32 > 16"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("QuotaExceeded")))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, &appsv1.StatefulSet{})).NotTo(Succeed())
+
+		By("Admitting the mesh once the quota is raised")
+		quota := &monarchv1alpha1.MonarchMeshQuota{}
+		Expect(k8sClient.Get(ctx, quotaName, quota)).To(Succeed())
+		quota.Spec.Resources[gpu] = resource.MustParse("32")
+		Expect(k8sClient.Update(ctx, quota)).To(Succeed())
+
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshQueued))
+		Expect(meta.IsStatusConditionFalse(mesh.Status.Conditions, monarchv1alpha1.MeshConditionQueued)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring("Admitted")))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, &appsv1.StatefulSet{})).To(Succeed())
+	})
+
+	It("should admit queued meshes once other meshes release quota", func() {
+		other := newMesh(otherName, 2)
+		Expect(k8sClient.Create(ctx, other)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, types.NamespacedName{Name: otherName, Namespace: "default"})
+		Expect(k8sClient.Create(ctx, newMesh(resourceName, 1))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshQueued))
+
+		By("Suspending the other mesh")
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: otherName, Namespace: "default"}, other)).To(Succeed())
+		other.Spec.Suspend = true
+		Expect(k8sClient.Update(ctx, other)).To(Succeed())
+
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshQueued))
+	})
+
+	It("should report the usage of the quota", func() {
+		Expect(k8sClient.Create(ctx, newMesh(otherName, 1))).To(Succeed())
+		queued := newMesh(resourceName, 2)
+		Expect(k8sClient.Create(ctx, queued)).To(Succeed())
+		queued.Status.Phase = monarchv1alpha1.MonarchMeshQueued
+		Expect(k8sClient.Status().Update(ctx, queued)).To(Succeed())
+
+		quotaReconciler := &MonarchMeshQuotaReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		_, err := quotaReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: quotaName})
+		Expect(err).NotTo(HaveOccurred())
+
+		quota := &monarchv1alpha1.MonarchMeshQuota{}
+		Expect(k8sClient.Get(ctx, quotaName, quota)).To(Succeed())
+		Expect(quota.Status.Used.Meshes).To(Equal(int32(1)))
+		Expect(quota.Status.Used.Replicas).To(Equal(int32(1)))
+		Expect(quota.Status.Used.Resources.Name(gpu, resource.DecimalSI).Equal(resource.MustParse("8"))).To(BeTrue())
+		Expect(quota.Status.Queued).To(Equal(int32(1)))
+	})
+})
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// workerServiceAccountName returns the name of the ServiceAccount created for mesh workers.
+func workerServiceAccountName(mesh *monarchv1alpha1.MonarchMesh) string {
+	return mesh.Name + "-worker"
+}
+
+// workerPodServiceAccount returns the name of the ServiceAccount the worker pods run as.
+func workerPodServiceAccount(mesh *monarchv1alpha1.MonarchMesh) string {
+	if name := mesh.Spec.PodTemplate.ServiceAccountName; name != "" {
+		return name
+	}
+	if mesh.Spec.RBAC != nil && mesh.Spec.RBAC.CreateWorkerServiceAccount {
+		return workerServiceAccountName(mesh)
+	}
+	return "default"
+}
+
+// clientRoleName returns the name of the Role and RoleBinding granting client access to the mesh.
+func clientRoleName(mesh *monarchv1alpha1.MonarchMesh) string {
+	return mesh.Name + "-client"
+}
+
+// reconcileRBAC creates the per-mesh worker ServiceAccount and the client Role and RoleBinding
+// requested in Spec.RBAC, and removes those that are no longer requested.
+//
+// The client Role is restricted with resourceNames to the MonarchMesh, its headless Service and
+// its worker pods, which is all a Monarch client needs to discover the mesh. Clients must use
+// field selectors on metadata.name to list or watch these objects. The status of the mesh is
+// read through the MonarchMesh itself: the operator may only grant what it holds, and it only
+// updates the status subresource.
+func (r *MonarchMeshReconciler) reconcileRBAC(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string, svcName string,
+) error {
+	var spec monarchv1alpha1.MeshRBAC
+	if mesh.Spec.RBAC != nil {
+		spec = *mesh.Spec.RBAC
+	}
+
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: workerServiceAccountName(mesh), Namespace: mesh.Namespace},
+	}
+	if spec.CreateWorkerServiceAccount {
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
+			sa.Labels = selectorLabels
+			return ctrl.SetControllerReference(mesh, sa, r.Scheme)
+		})
+		if err != nil {
+			return err
+		}
+	} else if err := r.deleteOwned(ctx, mesh, sa); err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: clientRoleName(mesh), Namespace: mesh.Namespace}}
+	binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: clientRoleName(mesh), Namespace: mesh.Namespace}}
+	if spec.ClientServiceAccountName == "" {
+		if err := r.deleteOwned(ctx, mesh, binding); err != nil {
+			return err
+		}
+		return r.deleteOwned(ctx, mesh, role)
+	}
+
+	readVerbs := []string{"get", "list", "watch"}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Labels = selectorLabels
+		role.Rules = []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{monarchv1alpha1.GroupVersion.Group},
+				Resources:     []string{"monarchmeshes"},
+				ResourceNames: []string{mesh.Name},
+				Verbs:         readVerbs,
+			},
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"services"},
+				ResourceNames: []string{svcName},
+				Verbs:         readVerbs,
+			},
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"pods"},
+				ResourceNames: workerPodNames(mesh),
+				Verbs:         readVerbs,
+			},
+		}
+		return ctrl.SetControllerReference(mesh, role, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, binding, func() error {
+		binding.Labels = selectorLabels
+		// RoleRef is immutable, but it always points at the Role created above.
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name}
+		binding.Subjects = []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      spec.ClientServiceAccountName,
+			Namespace: mesh.Namespace,
+		}}
+		return ctrl.SetControllerReference(mesh, binding, r.Scheme)
+	})
+	return err
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh RBAC", func() {
+	const resourceName = "rbac-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		workerSAName       types.NamespacedName
+		clientRoleName     types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		workerSAName = types.NamespacedName{Name: resourceName + "-worker", Namespace: "default"}
+		clientRoleName = types.NamespacedName{Name: resourceName + "-client", Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, workerSAName, &corev1.ServiceAccount{})
+		deleteIfExists(ctx, clientRoleName, &rbacv1.Role{}, &rbacv1.RoleBinding{})
+	})
+
+	newMesh := func(rbac *monarchv1alpha1.MeshRBAC) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.RBAC = rbac
+		return mesh
+	}
+
+	It("should not create RBAC objects by default", func() {
+		Expect(k8sClient.Create(ctx, newMesh(nil))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, workerSAName, &corev1.ServiceAccount{}))).To(BeTrue())
+		Expect(errors.IsNotFound(k8sClient.Get(ctx, clientRoleName, &rbacv1.Role{}))).To(BeTrue())
+	})
+
+	It("should run workers under a per-mesh ServiceAccount", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshRBAC{CreateWorkerServiceAccount: true}))).
+			To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		sa := &corev1.ServiceAccount{}
+		Expect(k8sClient.Get(ctx, workerSAName, sa)).To(Succeed())
+		Expect(sa.OwnerReferences).To(HaveLen(1))
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.ServiceAccountName).To(Equal(workerSAName.Name))
+	})
+
+	It("should grant the client ServiceAccount access to this mesh only", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshRBAC{ClientServiceAccountName: "monarch-client"}))).
+			To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		role := &rbacv1.Role{}
+		Expect(k8sClient.Get(ctx, clientRoleName, role)).To(Succeed())
+		Expect(role.Rules).To(ContainElement(HaveField("ResourceNames", ConsistOf(resourceName))))
+		Expect(role.Rules).To(ContainElement(HaveField("ResourceNames",
+			ConsistOf(resourceName+reconciler.Config.ServiceSuffix))))
+		Expect(role.Rules).To(ContainElement(HaveField("ResourceNames",
+			ConsistOf(resourceName+"-0", resourceName+"-1"))))
+		for _, rule := range role.Rules {
+			Expect(rule.Verbs).To(ConsistOf("get", "list", "watch"))
+		}
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(k8sClient.Get(ctx, clientRoleName, binding)).To(Succeed())
+		Expect(binding.RoleRef.Name).To(Equal(clientRoleName.Name))
+		Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{
+			Kind: rbacv1.ServiceAccountKind, Name: "monarch-client", Namespace: "default",
+		}))
+
+		By("Scaling the mesh and verifying the pod names follow")
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.Replicas = 3
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, clientRoleName, role)).To(Succeed())
+		Expect(role.Rules).To(ContainElement(HaveField("ResourceNames",
+			ConsistOf(resourceName+"-0", resourceName+"-1", resourceName+"-2"))))
+	})
+
+	It("should only grant what the operator's ClusterRole holds", func() {
+		By("Running the reconciler as a ServiceAccount bound to the generated ClusterRole")
+		manifest, err := os.Open(filepath.Join("..", "..", "config", "rbac", "role.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = manifest.Close() }()
+		operatorRole := &rbacv1.ClusterRole{}
+		Expect(yaml.NewYAMLOrJSONDecoder(manifest, 4096).Decode(operatorRole)).To(Succeed())
+		operatorRole.Name = resourceName + "-operator"
+		operator := &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-operator", Namespace: "default"},
+		}
+		operatorBinding := &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-operator"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: operatorRole.Name},
+			Subjects: []rbacv1.Subject{{
+				Kind: rbacv1.ServiceAccountKind, Name: operator.Name, Namespace: operator.Namespace,
+			}},
+		}
+		for _, obj := range []client.Object{operatorRole, operator, operatorBinding} {
+			Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, obj)
+		}
+
+		impersonated := rest.CopyConfig(cfg)
+		impersonated.Impersonate = rest.ImpersonationConfig{
+			UserName: "system:serviceaccount:default:" + operator.Name,
+		}
+		reconciler.Client, err = client.New(impersonated, client.Options{Scheme: k8sClient.Scheme()})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshRBAC{
+			CreateWorkerServiceAccount: true, ClientServiceAccountName: "monarch-client",
+		}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, clientRoleName, &rbacv1.Role{})).To(Succeed())
+		Expect(k8sClient.Get(ctx, clientRoleName, &rbacv1.RoleBinding{})).To(Succeed())
+	})
+})
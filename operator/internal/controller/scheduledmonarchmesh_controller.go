@@ -0,0 +1,333 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/cron"
+)
+
+// ScheduledMonarchMeshReconciler creates the MonarchMeshes of a ScheduledMonarchMesh on its
+// cron schedule and deletes the finished ones beyond its history limits.
+type ScheduledMonarchMeshReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Clock tells the time the schedules are evaluated at. Defaults to the real clock; tests
+	// inject a fake one.
+	Clock clock.PassiveClock
+}
+
+// RBAC permissions for the schedule controller. Mesh and event access is granted to the
+// MonarchMesh controller.
+//
+// scheduledmonarchmeshes, scheduledmonarchmeshes/status (get;list;watch, get;update;patch):
+//   The controller reports the active meshes of each schedule and the time of its last run.
+
+// +kubebuilder:rbac:groups=monarch.pytorch.org,resources=scheduledmonarchmeshes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monarch.pytorch.org,resources=scheduledmonarchmeshes/status,verbs=get;update;patch
+
+// Reconcile creates the mesh of the last run due, if any, and requeues at the next run.
+//
+// The flow is:
+//  1. Classify the meshes owned by the schedule as active, succeeded or failed
+//  2. Delete the finished meshes beyond the history limits
+//  3. Stop there while the schedule is suspended
+//  4. Find the last run due since the last scheduled time, within the starting deadline
+//  5. Apply the concurrency policy to the active meshes
+//  6. Create the mesh of the run and requeue at the next run
+func (r *ScheduledMonarchMeshReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var scheduled monarchv1alpha1.ScheduledMonarchMesh
+	if err := r.Get(ctx, req.NamespacedName, &scheduled); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	status := scheduled.Status.DeepCopy()
+
+	// 1. Classify the meshes owned by the schedule.
+	active, succeeded, failed, err := r.ownedMeshes(ctx, &scheduled, status)
+	if err != nil {
+		log.Error(err, "Failed to list MonarchMeshes")
+		return ctrl.Result{}, err
+	}
+
+	// 2. Delete the finished meshes beyond the history limits, oldest first.
+	if err := r.pruneHistory(ctx, succeeded, scheduled.Spec.SuccessfulMeshesHistoryLimit); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.pruneHistory(ctx, failed, scheduled.Spec.FailedMeshesHistoryLimit); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 3. Stop there while the schedule is suspended.
+	if scheduled.Spec.Suspend {
+		return ctrl.Result{}, r.updateStatus(ctx, &scheduled, status)
+	}
+
+	// 4. Find the last run due. An invalid schedule won't become valid until the spec changes,
+	// which triggers a new reconcile.
+	schedule, location, err := parseSchedule(&scheduled)
+	if err != nil {
+		r.recordEvent(&scheduled, corev1.EventTypeWarning, "InvalidSchedule", err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &scheduled, status)
+	}
+	now := r.now().In(location)
+	run := lastRun(&scheduled, schedule, now)
+	result := ctrl.Result{}
+	if next := schedule.Next(now); !next.IsZero() {
+		result.RequeueAfter = next.Sub(now)
+	}
+	if run.IsZero() {
+		return result, r.updateStatus(ctx, &scheduled, status)
+	}
+
+	// 5. Apply the concurrency policy. A run skipped by Forbid is retried once the active meshes
+	// finish, as long as it is within the starting deadline.
+	skip, err := r.applyConcurrencyPolicy(ctx, &scheduled, status, active, run)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if skip {
+		log.Info("Skipping run while meshes are active", "run", run, "active", status.Active)
+		return result, r.updateStatus(ctx, &scheduled, status)
+	}
+
+	// 6. Create the mesh of the run.
+	mesh, err := r.scheduledMesh(&scheduled, run)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, mesh); err != nil && !apierrors.IsAlreadyExists(err) {
+		log.Error(err, "Failed to create MonarchMesh", "mesh", mesh.Name)
+		r.recordEvent(&scheduled, corev1.EventTypeWarning, "FailedCreate",
+			fmt.Sprintf("Failed to create MonarchMesh %s: %v", mesh.Name, err))
+		return ctrl.Result{}, err
+	} else if err == nil {
+		r.recordEvent(&scheduled, corev1.EventTypeNormal, "SuccessfulCreate", "Created MonarchMesh "+mesh.Name)
+	}
+	status.Active = append(status.Active, mesh.Name)
+	sort.Strings(status.Active)
+	status.Active = slices.Compact(status.Active)
+	status.LastScheduleTime = &metav1.Time{Time: run}
+	return result, r.updateStatus(ctx, &scheduled, status)
+}
+
+// ownedMeshes lists the meshes owned by scheduled that are not being deleted, and returns the
+// active, Succeeded and Failed ones. It records the active meshes and the completion of the last
+// Succeeded mesh in status.
+func (r *ScheduledMonarchMeshReconciler) ownedMeshes(
+	ctx context.Context, scheduled *monarchv1alpha1.ScheduledMonarchMesh, status *monarchv1alpha1.ScheduledMonarchMeshStatus,
+) (active, succeeded, failed []*monarchv1alpha1.MonarchMesh, err error) {
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	if err := r.List(ctx, meshes, client.InNamespace(scheduled.Namespace)); err != nil {
+		return nil, nil, nil, err
+	}
+	status.Active = nil
+	for i := range meshes.Items {
+		mesh := &meshes.Items[i]
+		if !metav1.IsControlledBy(mesh, scheduled) || !mesh.DeletionTimestamp.IsZero() {
+			continue
+		}
+		switch mesh.Status.Phase {
+		case monarchv1alpha1.MonarchMeshSucceeded:
+			succeeded = append(succeeded, mesh)
+			if completed := mesh.Status.CompletionTime; completed != nil &&
+				(status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(completed)) {
+				status.LastSuccessfulTime = completed.DeepCopy()
+			}
+		case monarchv1alpha1.MonarchMeshFailed:
+			failed = append(failed, mesh)
+		default:
+			active = append(active, mesh)
+			status.Active = append(status.Active, mesh.Name)
+		}
+	}
+	sort.Strings(status.Active)
+	return active, succeeded, failed, nil
+}
+
+// pruneHistory deletes the oldest of the finished meshes beyond limit. A nil limit keeps them all.
+func (r *ScheduledMonarchMeshReconciler) pruneHistory(
+	ctx context.Context, meshes []*monarchv1alpha1.MonarchMesh, limit *int32,
+) error {
+	if limit == nil || len(meshes) <= int(*limit) {
+		return nil
+	}
+	sort.Slice(meshes, func(i, j int) bool {
+		return finishedAt(meshes[i]).Before(finishedAt(meshes[j]))
+	})
+	for _, mesh := range meshes[:len(meshes)-int(*limit)] {
+		if err := r.deleteMesh(ctx, mesh); err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to delete finished MonarchMesh", "mesh", mesh.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+// applyConcurrencyPolicy applies the concurrency policy of scheduled to the active meshes when
+// run is due, and removes the replaced meshes from status. It returns true when the run must be
+// skipped.
+func (r *ScheduledMonarchMeshReconciler) applyConcurrencyPolicy(
+	ctx context.Context, scheduled *monarchv1alpha1.ScheduledMonarchMesh,
+	status *monarchv1alpha1.ScheduledMonarchMeshStatus, active []*monarchv1alpha1.MonarchMesh, run time.Time,
+) (bool, error) {
+	if len(active) == 0 {
+		return false, nil
+	}
+	switch scheduled.Spec.ConcurrencyPolicy {
+	case monarchv1alpha1.ConcurrencyForbid:
+		return true, nil
+	case monarchv1alpha1.ConcurrencyReplace:
+		for _, mesh := range active {
+			if err := r.deleteMesh(ctx, mesh); err != nil {
+				logf.FromContext(ctx).Error(err, "Failed to delete active MonarchMesh", "mesh", mesh.Name)
+				return false, err
+			}
+			r.recordEvent(scheduled, corev1.EventTypeNormal, "SuccessfulDelete",
+				fmt.Sprintf("Deleted MonarchMesh %s replaced by the run at %s", mesh.Name, run.Format(time.RFC3339)))
+		}
+		status.Active = nil
+	}
+	return false, nil
+}
+
+// now returns the current time of the reconciler's clock.
+func (r *ScheduledMonarchMeshReconciler) now() time.Time {
+	if r.Clock == nil {
+		return clock.RealClock{}.Now()
+	}
+	return r.Clock.Now()
+}
+
+// parseSchedule parses the schedule of scheduled and loads the location of its time zone.
+func parseSchedule(scheduled *monarchv1alpha1.ScheduledMonarchMesh) (*cron.Schedule, *time.Location, error) {
+	schedule, err := cron.Parse(scheduled.Spec.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %q: %w", scheduled.Spec.Schedule, err)
+	}
+	location := time.Local
+	if scheduled.Spec.TimeZone != nil {
+		if location, err = time.LoadLocation(*scheduled.Spec.TimeZone); err != nil {
+			return nil, nil, fmt.Errorf("invalid time zone %q: %w", *scheduled.Spec.TimeZone, err)
+		}
+	}
+	return schedule, location, nil
+}
+
+// lastRun returns the last run of schedule due at now since the last scheduled time of
+// scheduled, or its creation, or the zero time if there is none. Runs missed by more than
+// Spec.StartingDeadlineSeconds are not considered.
+func lastRun(scheduled *monarchv1alpha1.ScheduledMonarchMesh, schedule *cron.Schedule, now time.Time) time.Time {
+	earliest := scheduled.CreationTimestamp.Time
+	if scheduled.Status.LastScheduleTime != nil {
+		earliest = scheduled.Status.LastScheduleTime.Time
+	}
+	if deadline := scheduled.Spec.StartingDeadlineSeconds; deadline != nil {
+		if start := now.Add(-time.Duration(*deadline) * time.Second); start.After(earliest) {
+			earliest = start
+		}
+	}
+	var run time.Time
+	for t := schedule.Next(earliest.In(now.Location())); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		run = t
+	}
+	return run
+}
+
+// scheduledMesh returns the mesh of the run of scheduled at run, named after the scheduled time
+// in minutes so that a run creates at most one mesh.
+func (r *ScheduledMonarchMeshReconciler) scheduledMesh(
+	scheduled *monarchv1alpha1.ScheduledMonarchMesh, run time.Time,
+) (*monarchv1alpha1.MonarchMesh, error) {
+	template := &scheduled.Spec.MeshTemplate
+	mesh := &monarchv1alpha1.MonarchMesh{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", scheduled.Name, run.Unix()/60),
+			Namespace:   scheduled.Namespace,
+			Labels:      maps.Clone(template.Labels),
+			Annotations: maps.Clone(template.Annotations),
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+	if mesh.Annotations == nil {
+		mesh.Annotations = map[string]string{}
+	}
+	mesh.Annotations[monarchv1alpha1.ScheduledAtAnnotation] = run.Format(time.RFC3339)
+	if err := controllerutil.SetControllerReference(scheduled, mesh, r.Scheme); err != nil {
+		return nil, err
+	}
+	return mesh, nil
+}
+
+// deleteMesh deletes a mesh of the schedule, letting the garbage collector delete its workers.
+func (r *ScheduledMonarchMeshReconciler) deleteMesh(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) error {
+	return client.IgnoreNotFound(r.Delete(ctx, mesh, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
+
+// updateStatus writes status to scheduled if it changed.
+func (r *ScheduledMonarchMeshReconciler) updateStatus(
+	ctx context.Context, scheduled *monarchv1alpha1.ScheduledMonarchMesh, status *monarchv1alpha1.ScheduledMonarchMeshStatus,
+) error {
+	if apiequality.Semantic.DeepEqual(&scheduled.Status, status) {
+		return nil
+	}
+	scheduled.Status = *status
+	if err := r.Status().Update(ctx, scheduled); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to update ScheduledMonarchMesh status")
+		return err
+	}
+	return nil
+}
+
+// recordEvent records an event on the schedule if the reconciler has a recorder.
+func (r *ScheduledMonarchMeshReconciler) recordEvent(
+	scheduled *monarchv1alpha1.ScheduledMonarchMesh, eventType, reason, message string,
+) {
+	if r.Recorder != nil {
+		r.Recorder.Event(scheduled, eventType, reason, message)
+	}
+}
+
+// finishedAt returns the time a finished mesh completed, or its creation time if unknown.
+func finishedAt(mesh *monarchv1alpha1.MonarchMesh) time.Time {
+	if mesh.Status.CompletionTime != nil {
+		return mesh.Status.CompletionTime.Time
+	}
+	return mesh.CreationTimestamp.Time
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ScheduledMonarchMeshReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&monarchv1alpha1.ScheduledMonarchMesh{}).
+		// Meshes finishing or being deleted change the active meshes and history of the schedule.
+		Owns(&monarchv1alpha1.MonarchMesh{}).
+		Complete(r)
+}
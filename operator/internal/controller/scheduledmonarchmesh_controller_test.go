@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("ScheduledMonarchMesh Controller", func() {
+	const name = "scheduled-test"
+
+	var (
+		ctx        context.Context
+		key        types.NamespacedName
+		clock      *clocktesting.FakePassiveClock
+		reconciler *ScheduledMonarchMeshReconciler
+		recorder   *record.FakeRecorder
+		// firstRun is the first hourly run after the creation of the schedule.
+		firstRun time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		key = types.NamespacedName{Name: name, Namespace: "default"}
+		recorder = record.NewFakeRecorder(10)
+		clock = clocktesting.NewFakePassiveClock(time.Now())
+		reconciler = &ScheduledMonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: recorder,
+			Clock:    clock,
+		}
+	})
+
+	AfterEach(func() {
+		meshes := &monarchv1alpha1.MonarchMeshList{}
+		Expect(k8sClient.List(ctx, meshes, client.InNamespace(key.Namespace))).To(Succeed())
+		for i := range meshes.Items {
+			if meshes.Items[i].Annotations[monarchv1alpha1.ScheduledAtAnnotation] != "" {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &meshes.Items[i]))).To(Succeed())
+			}
+		}
+		deleteIfExists(ctx, key, &monarchv1alpha1.ScheduledMonarchMesh{})
+	})
+
+	createSchedule := func(mutate func(*monarchv1alpha1.ScheduledMonarchMesh)) *monarchv1alpha1.ScheduledMonarchMesh {
+		scheduled := &monarchv1alpha1.ScheduledMonarchMesh{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: monarchv1alpha1.ScheduledMonarchMeshSpec{
+				Schedule: "0 * * * *",
+				TimeZone: ptr.To("UTC"),
+				MeshTemplate: monarchv1alpha1.ScheduledMeshTemplate{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"suite": "nightly-eval"}},
+					Spec: monarchv1alpha1.MonarchMeshSpec{
+						Replicas: 2,
+						PodTemplate: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}},
+						},
+					},
+				},
+			},
+		}
+		if mutate != nil {
+			mutate(scheduled)
+		}
+		Expect(k8sClient.Create(ctx, scheduled)).To(Succeed())
+		firstRun = scheduled.CreationTimestamp.UTC().Truncate(time.Hour).Add(time.Hour)
+		return scheduled
+	}
+
+	// reconcileAt reconciles the schedule with the clock set to t.
+	reconcileAt := func(t time.Time) reconcile.Result {
+		clock.SetTime(t)
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	meshName := func(run time.Time) string {
+		return fmt.Sprintf("%s-%d", name, run.Unix()/60)
+	}
+
+	scheduledMeshes := func() []string {
+		meshes := &monarchv1alpha1.MonarchMeshList{}
+		Expect(k8sClient.List(ctx, meshes, client.InNamespace(key.Namespace))).To(Succeed())
+		var names []string
+		for _, mesh := range meshes.Items {
+			if mesh.Annotations[monarchv1alpha1.ScheduledAtAnnotation] != "" && mesh.DeletionTimestamp.IsZero() {
+				names = append(names, mesh.Name)
+			}
+		}
+		return names
+	}
+
+	finish := func(name string, phase monarchv1alpha1.MonarchMeshPhase, at time.Time) {
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: key.Namespace}, mesh)).To(Succeed())
+		mesh.Status.Phase = phase
+		mesh.Status.CompletionTime = &metav1.Time{Time: at}
+		Expect(k8sClient.Status().Update(ctx, mesh)).To(Succeed())
+	}
+
+	It("should create a timestamped mesh owned by the schedule at each run", func() {
+		scheduled := createSchedule(nil)
+
+		By("Waiting for the first run")
+		result := reconcileAt(firstRun.Add(-10 * time.Minute))
+		Expect(result.RequeueAfter).To(Equal(10 * time.Minute))
+		Expect(scheduledMeshes()).To(BeEmpty())
+
+		By("Creating the mesh of the run")
+		result = reconcileAt(firstRun.Add(time.Minute))
+		Expect(result.RequeueAfter).To(Equal(59 * time.Minute))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Created MonarchMesh " + meshName(firstRun))))
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: meshName(firstRun), Namespace: key.Namespace}, mesh)).
+			To(Succeed())
+		Expect(metav1.IsControlledBy(mesh, scheduled)).To(BeTrue())
+		Expect(mesh.Labels).To(HaveKeyWithValue("suite", "nightly-eval"))
+		Expect(mesh.Annotations).To(HaveKeyWithValue(monarchv1alpha1.ScheduledAtAnnotation, firstRun.Format(time.RFC3339)))
+		Expect(mesh.Spec.Replicas).To(Equal(int32(2)))
+
+		Expect(k8sClient.Get(ctx, key, scheduled)).To(Succeed())
+		Expect(scheduled.Status.Active).To(Equal([]string{meshName(firstRun)}))
+		Expect(scheduled.Status.LastScheduleTime.Time).To(BeTemporally("==", firstRun))
+
+		By("Not creating the mesh of a run twice")
+		reconcileAt(firstRun.Add(2 * time.Minute))
+		Expect(scheduledMeshes()).To(HaveLen(1))
+	})
+
+	It("should skip runs while a mesh is active with the Forbid policy", func() {
+		createSchedule(func(scheduled *monarchv1alpha1.ScheduledMonarchMesh) {
+			scheduled.Spec.ConcurrencyPolicy = monarchv1alpha1.ConcurrencyForbid
+		})
+		reconcileAt(firstRun)
+		reconcileAt(firstRun.Add(time.Hour))
+		Expect(scheduledMeshes()).To(ConsistOf(meshName(firstRun)))
+
+		By("Creating the mesh of the skipped run once the active mesh finishes")
+		finish(meshName(firstRun), monarchv1alpha1.MonarchMeshSucceeded, firstRun.Add(90*time.Minute))
+		reconcileAt(firstRun.Add(90 * time.Minute))
+		Expect(scheduledMeshes()).To(ConsistOf(meshName(firstRun), meshName(firstRun.Add(time.Hour))))
+	})
+
+	It("should replace the active meshes with the Replace policy", func() {
+		createSchedule(func(scheduled *monarchv1alpha1.ScheduledMonarchMesh) {
+			scheduled.Spec.ConcurrencyPolicy = monarchv1alpha1.ConcurrencyReplace
+		})
+		reconcileAt(firstRun)
+		reconcileAt(firstRun.Add(time.Hour))
+		Expect(scheduledMeshes()).To(ConsistOf(meshName(firstRun.Add(time.Hour))))
+	})
+
+	It("should skip runs missed by more than the starting deadline", func() {
+		createSchedule(func(scheduled *monarchv1alpha1.ScheduledMonarchMesh) {
+			scheduled.Spec.StartingDeadlineSeconds = ptr.To[int64](60)
+		})
+		reconcileAt(firstRun.Add(5 * time.Minute))
+		Expect(scheduledMeshes()).To(BeEmpty())
+	})
+
+	It("should delete the finished meshes beyond the history limits", func() {
+		createSchedule(func(scheduled *monarchv1alpha1.ScheduledMonarchMesh) {
+			scheduled.Spec.SuccessfulMeshesHistoryLimit = ptr.To[int32](1)
+			scheduled.Spec.FailedMeshesHistoryLimit = ptr.To[int32](0)
+		})
+		runs := []time.Time{firstRun, firstRun.Add(time.Hour), firstRun.Add(2 * time.Hour)}
+		for _, run := range runs {
+			reconcileAt(run)
+		}
+		finish(meshName(runs[0]), monarchv1alpha1.MonarchMeshSucceeded, runs[0].Add(10*time.Minute))
+		finish(meshName(runs[1]), monarchv1alpha1.MonarchMeshSucceeded, runs[1].Add(10*time.Minute))
+		finish(meshName(runs[2]), monarchv1alpha1.MonarchMeshFailed, runs[2].Add(10*time.Minute))
+
+		reconcileAt(runs[2].Add(20 * time.Minute))
+		Expect(scheduledMeshes()).To(ConsistOf(meshName(runs[1])))
+		scheduled := &monarchv1alpha1.ScheduledMonarchMesh{}
+		Expect(k8sClient.Get(ctx, key, scheduled)).To(Succeed())
+		Expect(scheduled.Status.Active).To(BeEmpty())
+		Expect(scheduled.Status.LastSuccessfulTime.Time).To(BeTemporally("==", runs[1].Add(10*time.Minute)))
+	})
+})
@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// envSharedStoragePath is the mount path of Spec.SharedStorage in the worker containers.
+const envSharedStoragePath = "MONARCH_SHARED_STORAGE_PATH"
+
+// sharedClaimName returns the name of the claim mounted as shared storage.
+func sharedClaimName(mesh *monarchv1alpha1.MonarchMesh) string {
+	if mesh.Spec.SharedStorage.ClaimName != "" {
+		return mesh.Spec.SharedStorage.ClaimName
+	}
+	return mesh.Name + "-shared"
+}
+
+// reconcileSharedStorage creates or adopts the claim of Spec.SharedStorage and applies its
+// retention policy. The mesh is an owner of the claim, and the claim thus garbage-collected
+// with the mesh, only with the Delete retention policy. The claim is not a controlled resource
+// of the mesh, so adopting a claim doesn't conflict with its existing controller.
+//
+// A claim the mesh stops using, since Spec.SharedStorage was removed or names another claim,
+// is released: the mesh is removed from its owners, so it outlives the mesh.
+func (r *MonarchMeshReconciler) reconcileSharedStorage(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) error {
+	spec := mesh.Spec.SharedStorage
+	previous := mesh.Status.SharedStorage
+	if spec == nil {
+		mesh.Status.SharedStorage = nil
+		if previous != nil {
+			return r.releaseSharedClaim(ctx, mesh, previous.ClaimName)
+		}
+		return nil
+	}
+	status := &monarchv1alpha1.SharedStorageStatus{ClaimName: sharedClaimName(mesh)}
+	mesh.Status.SharedStorage = status
+	if previous != nil && previous.ClaimName != status.ClaimName {
+		if err := r.releaseSharedClaim(ctx, mesh, previous.ClaimName); err != nil {
+			return err
+		}
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: status.ClaimName, Namespace: mesh.Namespace},
+	}
+	if spec.ClaimName != "" {
+		err := r.Get(ctx, types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, pvc)
+		if apierrors.IsNotFound(err) {
+			r.recordEvent(mesh, corev1.EventTypeWarning, "SharedStorageNotFound",
+				fmt.Sprintf("Shared storage claim %s does not exist", pvc.Name))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, pvc, func() error {
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Labels = selectorLabels
+			pvc.Spec = *spec.Spec.DeepCopy()
+			if len(pvc.Spec.AccessModes) == 0 {
+				pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+			}
+		}
+		if spec.RetentionPolicy == monarchv1alpha1.SharedStorageDelete {
+			return controllerutil.SetOwnerReference(mesh, pvc, r.Scheme)
+		}
+		removeOwnerReference(pvc, mesh)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	status.Phase = pvc.Status.Phase
+	return nil
+}
+
+// releaseSharedClaim removes the mesh from the owners of a claim it no longer mounts.
+func (r *MonarchMeshReconciler) releaseSharedClaim(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, name string,
+) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: mesh.Namespace}, pvc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(pvc.DeepCopy())
+	if !removeOwnerReference(pvc, mesh) {
+		return nil
+	}
+	return r.Patch(ctx, pvc, patch)
+}
+
+// removeOwnerReference removes the mesh from the owners of obj, and reports whether it was one.
+func removeOwnerReference(obj metav1.Object, mesh *monarchv1alpha1.MonarchMesh) bool {
+	refs := obj.GetOwnerReferences()
+	kept := slices.DeleteFunc(slices.Clone(refs), func(ref metav1.OwnerReference) bool {
+		return ref.UID == mesh.UID
+	})
+	if len(kept) == len(refs) {
+		return false
+	}
+	obj.SetOwnerReferences(kept)
+	return true
+}
+
+// injectSharedStorage mounts the shared storage claim in all worker containers.
+func (r *MonarchMeshReconciler) injectSharedStorage(spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh) {
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: r.Config.SharedStorageVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: sharedClaimName(mesh)},
+		},
+	})
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+			Name: r.Config.SharedStorageVolumeName, MountPath: r.Config.SharedStorageMountPath,
+		})
+		c.Env = append(c.Env, corev1.EnvVar{Name: envSharedStoragePath, Value: r.Config.SharedStorageMountPath})
+	}
+}
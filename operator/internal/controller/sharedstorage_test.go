@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh shared storage", func() {
+	const resourceName = "shared-storage-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		for _, name := range []string{resourceName + "-shared", "checkpoints"} {
+			deleteIfExists(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &corev1.PersistentVolumeClaim{})
+		}
+	})
+
+	newMesh := func(storage *monarchv1alpha1.MeshSharedStorage) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 1)
+		mesh.Spec.SharedStorage = storage
+		return mesh
+	}
+
+	claimSpec := corev1.PersistentVolumeClaimSpec{
+		Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Ti")},
+		},
+	}
+
+	It("should create a ReadWriteMany claim owned by the mesh with the Delete policy", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshSharedStorage{
+			Spec: &claimSpec, RetentionPolicy: monarchv1alpha1.SharedStorageDelete,
+		}))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.SharedStorage).NotTo(BeNil())
+		Expect(mesh.Status.SharedStorage.ClaimName).To(Equal(resourceName + "-shared"))
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-shared", Namespace: "default"}, pvc)).
+			To(Succeed())
+		Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteMany))
+		Expect(pvc.OwnerReferences).To(ContainElement(HaveField("UID", mesh.UID)))
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: reconciler.Config.SharedStorageVolumeName, MountPath: reconciler.Config.SharedStorageMountPath,
+		}))
+
+		By("Releasing the claim when the retention policy changes to Retain")
+		mesh.Spec.SharedStorage.RetentionPolicy = monarchv1alpha1.SharedStorageRetain
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-shared", Namespace: "default"}, pvc)).
+			To(Succeed())
+		Expect(pvc.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should release the claim when the shared storage is removed", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshSharedStorage{
+			Spec: &claimSpec, RetentionPolicy: monarchv1alpha1.SharedStorageDelete,
+		}))).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh.Spec.SharedStorage = nil
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.SharedStorage).To(BeNil())
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-shared", Namespace: "default"}, pvc)).
+			To(Succeed())
+		Expect(pvc.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should adopt an existing claim without taking ownership with the Retain policy", func() {
+		Expect(k8sClient.Create(ctx, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "checkpoints", Namespace: "default"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+				Resources:   claimSpec.Resources,
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshSharedStorage{ClaimName: "checkpoints"}))).
+			To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.SharedStorage).To(Equal(&monarchv1alpha1.SharedStorageStatus{
+			ClaimName: "checkpoints", Phase: corev1.ClaimPending,
+		}))
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "checkpoints", Namespace: "default"}, pvc)).To(Succeed())
+		Expect(pvc.OwnerReferences).To(BeEmpty())
+	})
+})
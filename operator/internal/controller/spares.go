@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// Values of the role label on workers of a mesh with Spec.Spares.
+const (
+	roleMember = "member"
+	roleSpare  = "spare"
+)
+
+// workerCount returns the number of worker pods of the mesh, including spares.
+func workerCount(mesh *monarchv1alpha1.MonarchMesh) int32 {
+	return mesh.Spec.Replicas + mesh.Spec.Spares
+}
+
+// serviceSelector returns the selector of the headless Service. With spares, only the workers
+// backing a rank are published.
+func (r *MonarchMeshReconciler) serviceSelector(
+	mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) map[string]string {
+	if mesh.Spec.Spares == 0 {
+		return selectorLabels
+	}
+	selector := maps.Clone(selectorLabels)
+	selector[r.Config.RoleLabelKey] = roleMember
+	return selector
+}
+
+// rankPodNames returns the pod backing each rank, indexed by rank. It follows Status.Ranks if
+// it matches the current spec, and maps every rank to the pod with the same ordinal otherwise.
+func rankPodNames(mesh *monarchv1alpha1.MonarchMesh) []string {
+	workers := workerPodNames(mesh)
+	names := workers[:mesh.Spec.Replicas]
+	if len(mesh.Status.Ranks) != int(mesh.Spec.Replicas) {
+		return names
+	}
+	valid := make(map[string]bool, len(workers))
+	for _, name := range workers {
+		valid[name] = true
+	}
+	current := make([]string, mesh.Spec.Replicas)
+	for _, rank := range mesh.Status.Ranks {
+		if rank.Rank < 0 || rank.Rank >= mesh.Spec.Replicas || !valid[rank.Pod] || current[rank.Rank] != "" {
+			return names
+		}
+		current[rank.Rank] = rank.Pod
+	}
+	return current
+}
+
+// assignRanks returns the pod backing each rank after promoting ready spares into the ranks
+// whose pod is not ready, together with a message per promotion. Spares are only promoted
+// while the mesh is Running, so that ranks are not shuffled while workers start up.
+func assignRanks(mesh *monarchv1alpha1.MonarchMesh, ready map[string]bool) ([]string, []string) {
+	names := rankPodNames(mesh)
+	if mesh.Status.Phase != monarchv1alpha1.MonarchMeshRunning {
+		return names, nil
+	}
+	assigned := make(map[string]bool, len(names))
+	for _, name := range names {
+		assigned[name] = true
+	}
+	var spares []string
+	for _, name := range workerPodNames(mesh) {
+		if !assigned[name] && ready[name] {
+			spares = append(spares, name)
+		}
+	}
+	var promotions []string
+	for rank, name := range names {
+		if ready[name] || len(spares) == 0 {
+			continue
+		}
+		names[rank], spares = spares[0], spares[1:]
+		promotions = append(promotions,
+			fmt.Sprintf("Promoted spare %s into rank %d, replacing %s", names[rank], rank, name))
+	}
+	return names, promotions
+}
+
+// reconcileSpares promotes ready spares into ranks whose pod failed, labels every worker as a
+// rank member or spare, and reports the pod backing each rank in Status.Ranks.
+func (r *MonarchMeshReconciler) reconcileSpares(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) error {
+	if mesh.Spec.Spares == 0 {
+		mesh.Status.Ranks = rankStatuses(rankPodNames(mesh))
+		return nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(mesh.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return err
+	}
+	ready := make(map[string]bool, len(pods.Items))
+	for i := range pods.Items {
+		ready[pods.Items[i].Name] = podReady(&pods.Items[i])
+	}
+
+	names, promotions := assignRanks(mesh, ready)
+	for _, message := range promotions {
+		r.recordEvent(mesh, corev1.EventTypeNormal, "SparePromoted", message)
+	}
+
+	rankOf := make(map[string]int, len(names))
+	for rank, name := range names {
+		rankOf[name] = rank
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		role, rankLabel := roleSpare, ""
+		if rank, ok := rankOf[pod.Name]; ok {
+			role, rankLabel = roleMember, strconv.Itoa(rank)
+		}
+		if pod.Labels[r.Config.RoleLabelKey] == role && pod.Labels[r.Config.RankLabelKey] == rankLabel {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[r.Config.RoleLabelKey] = role
+		if rankLabel == "" {
+			delete(pod.Labels, r.Config.RankLabelKey)
+		} else {
+			pod.Labels[r.Config.RankLabelKey] = rankLabel
+		}
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			return err
+		}
+	}
+
+	mesh.Status.Ranks = rankStatuses(names)
+	return nil
+}
+
+// rankStatuses converts pod names indexed by rank into Status.Ranks.
+func rankStatuses(names []string) []monarchv1alpha1.RankStatus {
+	ranks := make([]monarchv1alpha1.RankStatus, 0, len(names))
+	for rank, name := range names {
+		ranks = append(ranks, monarchv1alpha1.RankStatus{Rank: int32(rank), Pod: name})
+	}
+	return ranks
+}
+
+// podReady reports whether the pod is ready and not being deleted.
+func podReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
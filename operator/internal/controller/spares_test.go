@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh spares", func() {
+	const resourceName = "spares-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		recorder           *record.FakeRecorder
+		typeNamespacedName types.NamespacedName
+		svcKey             types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: recorder,
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		svcKey = types.NamespacedName{Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, svcKey, &corev1.Service{})
+		for i := range 3 {
+			deleteIfExists(ctx, types.NamespacedName{
+				Name: fmt.Sprintf("%s-%d", resourceName, i), Namespace: "default",
+			}, &corev1.Pod{})
+		}
+	})
+
+	// setWorkerReady creates the worker pod if needed and sets its Ready condition, since
+	// envtest runs neither the StatefulSet controller nor a kubelet.
+	setWorkerReady := func(ordinal int, ready bool) {
+		key := types.NamespacedName{Name: fmt.Sprintf("%s-%d", resourceName, ordinal), Namespace: "default"}
+		pod := &corev1.Pod{}
+		if err := k8sClient.Get(ctx, key, pod); err != nil {
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: map[string]string{
+					reconciler.Config.MeshLabelKey: resourceName,
+					reconciler.Config.AppLabelKey:  reconciler.Config.AppLabelValue,
+				}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		}
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	}
+
+	setReadyReplicas := func(ready int32) {
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		ss.Status.Replicas = *ss.Spec.Replicas
+		ss.Status.ReadyReplicas = ready
+		Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+	}
+
+	podLabels := func(ordinal int) map[string]string {
+		pod := &corev1.Pod{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{
+			Name: fmt.Sprintf("%s-%d", resourceName, ordinal), Namespace: "default",
+		}, pod)).To(Succeed())
+		return pod.Labels
+	}
+
+	It("should keep spares out of the Service and promote them into failed ranks", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.Spares = 1
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(*ss.Spec.Replicas).To(Equal(int32(3)))
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, svcKey, svc)).To(Succeed())
+		Expect(svc.Spec.Selector).To(HaveKeyWithValue(reconciler.Config.RoleLabelKey, "member"))
+
+		for i := range 3 {
+			setWorkerReady(i, true)
+		}
+		setReadyReplicas(3)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshRunning))
+		Expect(mesh.Status.Ranks).To(Equal([]monarchv1alpha1.RankStatus{
+			{Rank: 0, Pod: resourceName + "-0"}, {Rank: 1, Pod: resourceName + "-1"},
+		}))
+		Expect(podLabels(1)).To(HaveKeyWithValue(reconciler.Config.RankLabelKey, "1"))
+		Expect(podLabels(2)).To(HaveKeyWithValue(reconciler.Config.RoleLabelKey, "spare"))
+
+		By("Failing the worker backing rank 1")
+		setWorkerReady(1, false)
+		setReadyReplicas(2)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshRunning))
+		Expect(mesh.Status.Ranks).To(ContainElement(monarchv1alpha1.RankStatus{Rank: 1, Pod: resourceName + "-2"}))
+		Expect(podLabels(2)).To(HaveKeyWithValue(reconciler.Config.RankLabelKey, "1"))
+		Expect(podLabels(1)).To(HaveKeyWithValue(reconciler.Config.RoleLabelKey, "spare"))
+		Expect(podLabels(1)).NotTo(HaveKey(reconciler.Config.RankLabelKey))
+		Expect(recorder.Events).To(Receive(ContainSubstring("SparePromoted")))
+	})
+})
+
+var _ = Describe("Rank assignment", func() {
+	newMesh := func(phase monarchv1alpha1.MonarchMeshPhase) *monarchv1alpha1.MonarchMesh {
+		return &monarchv1alpha1.MonarchMesh{
+			ObjectMeta: metav1.ObjectMeta{Name: "mesh"},
+			Spec:       monarchv1alpha1.MonarchMeshSpec{Replicas: 2, Spares: 2},
+			Status:     monarchv1alpha1.MonarchMeshStatus{Phase: phase},
+		}
+	}
+
+	It("should not promote spares before the mesh is Running", func() {
+		names, promotions := assignRanks(newMesh(monarchv1alpha1.MonarchMeshPending), map[string]bool{"mesh-2": true})
+		Expect(names).To(Equal([]string{"mesh-0", "mesh-1"}))
+		Expect(promotions).To(BeEmpty())
+	})
+
+	It("should promote ready spares in ordinal order and keep earlier promotions", func() {
+		mesh := newMesh(monarchv1alpha1.MonarchMeshRunning)
+		mesh.Status.Ranks = rankStatuses([]string{"mesh-3", "mesh-1"})
+		names, promotions := assignRanks(mesh, map[string]bool{"mesh-0": true, "mesh-2": true, "mesh-3": true})
+		Expect(names).To(Equal([]string{"mesh-3", "mesh-0"}))
+		Expect(promotions).To(HaveLen(1))
+	})
+
+	It("should reset ranks that refer to pods outside the mesh", func() {
+		mesh := newMesh(monarchv1alpha1.MonarchMeshPending)
+		mesh.Status.Ranks = rankStatuses([]string{"mesh-7", "mesh-1"})
+		Expect(rankPodNames(mesh)).To(Equal([]string{"mesh-0", "mesh-1"}))
+	})
+})
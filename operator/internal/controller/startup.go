@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// Blockers of a worker that does not become ready, used as reasons of the StartupBlocked condition.
+const (
+	blockerUnschedulable = "Unschedulable"
+	blockerImagePull     = "ImagePull"
+	blockerCrashLoop     = "CrashLoop"
+	blockerNotReady      = "NotReady"
+)
+
+// classifyBlocker returns what keeps the pod from becoming ready, and details about it.
+func classifyBlocker(pod *corev1.Pod) (string, string) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return blockerUnschedulable, condition.Message
+		}
+	}
+	statuses := slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+				return blockerImagePull, fmt.Sprintf("container %s: %s", status.Name, waiting.Message)
+			case "CrashLoopBackOff":
+				return blockerCrashLoop, fmt.Sprintf("container %s restarted %d times", status.Name, status.RestartCount)
+			}
+		}
+	}
+	for _, status := range statuses {
+		if status.RestartCount > 0 {
+			return blockerCrashLoop, fmt.Sprintf("container %s restarted %d times", status.Name, status.RestartCount)
+		}
+	}
+	return blockerNotReady, fmt.Sprintf("pod is %s", pod.Status.Phase)
+}
+
+// reconcileStartupTimeout reports the ranks whose worker did not become ready within
+// Spec.StartupTimeout of its creation in the StartupBlocked condition, and applies
+// Spec.StartupTimeoutAction to them. Workers are marked with the StartedAnnotation once ready,
+// so that a worker becoming unready later, e.g. failing its readiness probe under load, is not
+// mistaken for a stuck one. It returns the time until the next worker times out.
+func (r *MonarchMeshReconciler) reconcileStartupTimeout(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) (time.Duration, error) {
+	if mesh.Spec.StartupTimeout == nil {
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionStartupBlocked)
+		return 0, nil
+	}
+	if isFinished(mesh) || mesh.Spec.Suspend {
+		return 0, nil
+	}
+
+	now := time.Now()
+	timeout := mesh.Spec.StartupTimeout.Duration
+	var timeoutIn time.Duration
+	var stuck []*corev1.Pod
+	var blockers, details []string
+	for _, rank := range mesh.Status.Ranks {
+		pod := &corev1.Pod{}
+		err := r.Get(ctx, types.NamespacedName{Name: rank.Pod, Namespace: mesh.Namespace}, pod)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if pod.DeletionTimestamp != nil || pod.Annotations[monarchv1alpha1.StartedAnnotation] != "" {
+			continue
+		}
+		if podReady(pod) {
+			if err := r.markStarted(ctx, pod, now); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if remaining := pod.CreationTimestamp.Add(timeout).Sub(now); remaining > 0 {
+			timeoutIn = earliest(timeoutIn, remaining)
+			continue
+		}
+		blocker, detail := classifyBlocker(pod)
+		stuck = append(stuck, pod)
+		blockers = append(blockers, blocker)
+		details = append(details, fmt.Sprintf("rank %d (%s): %s: %s", rank.Rank, pod.Name, blocker, detail))
+	}
+
+	if len(stuck) == 0 {
+		meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+			Type:    monarchv1alpha1.MeshConditionStartupBlocked,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotBlocked",
+			Message: fmt.Sprintf("No worker has been starting for longer than %s", timeout),
+		})
+		return timeoutIn, nil
+	}
+
+	message := fmt.Sprintf("Workers not ready after %s: %s", timeout, strings.Join(details, "; "))
+	previous := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionStartupBlocked)
+	if previous == nil || previous.Status != metav1.ConditionTrue || previous.Reason != blockers[0] {
+		r.recordEvent(mesh, corev1.EventTypeWarning, "StartupBlocked", message)
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionStartupBlocked,
+		Status:  metav1.ConditionTrue,
+		Reason:  blockers[0],
+		Message: message,
+	})
+
+	switch mesh.Spec.StartupTimeoutAction {
+	case monarchv1alpha1.StartupTimeoutRecreate:
+		for _, pod := range stuck {
+			if err := r.Delete(ctx, pod, client.Preconditions{UID: &pod.UID}); client.IgnoreNotFound(err) != nil {
+				return 0, err
+			}
+		}
+		r.recordEvent(mesh, corev1.EventTypeNormal, "StuckWorkersRecreated",
+			fmt.Sprintf("Recreating %d workers stuck past the startup timeout", len(stuck)))
+	case monarchv1alpha1.StartupTimeoutFail:
+		finishMesh(mesh, monarchv1alpha1.MonarchMeshFailed, "StartupTimeout", message)
+	}
+	return timeoutIn, nil
+}
+
+// markStarted sets the StartedAnnotation on a worker that is ready.
+func (r *MonarchMeshReconciler) markStarted(ctx context.Context, pod *corev1.Pod, now time.Time) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[monarchv1alpha1.StartedAnnotation] = now.UTC().Format(time.RFC3339)
+	return client.IgnoreNotFound(r.Patch(ctx, pod, patch))
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh startup timeout", func() {
+	const resourceName = "startup-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		podNamespacedName  types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		podNamespacedName = types.NamespacedName{Name: resourceName + "-0", Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, podNamespacedName, &corev1.Pod{})
+	})
+
+	// createStuckWorker creates the worker of rank 0 with the given status, as the StatefulSet
+	// controller and the kubelet would.
+	createStuckWorker := func(status corev1.PodStatus) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podNamespacedName.Name, Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}}},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status = status
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	}
+
+	createMesh := func(action monarchv1alpha1.StartupTimeoutAction) {
+		mesh := newTestMesh(typeNamespacedName, 1)
+		mesh.Spec.StartupTimeout = &metav1.Duration{Duration: time.Millisecond}
+		mesh.Spec.StartupTimeoutAction = action
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+	}
+
+	It("should fail the mesh when a worker stays unschedulable", func() {
+		createStuckWorker(corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{{
+			Type: corev1.PodScheduled, Status: corev1.ConditionFalse,
+			Reason: corev1.PodReasonUnschedulable, Message: "0/3 nodes are available: insufficient nvidia.com/gpu",
+		}}})
+		createMesh(monarchv1alpha1.StartupTimeoutFail)
+
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionStartupBlocked)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(blockerUnschedulable))
+		Expect(condition.Message).To(ContainSubstring("insufficient nvidia.com/gpu"))
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshFailed))
+	})
+
+	It("should recreate a worker stuck pulling its image", func() {
+		createStuckWorker(corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "worker",
+			Image: "monarch:latest",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "ImagePullBackOff", Message: "Back-off pulling image",
+			}},
+		}}})
+		createMesh(monarchv1alpha1.StartupTimeoutRecreate)
+
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionStartupBlocked).Reason).
+			To(Equal(blockerImagePull))
+		Expect(mesh.Status.Phase).To(Equal(monarchv1alpha1.MonarchMeshPending))
+		pod := &corev1.Pod{}
+		err := k8sClient.Get(ctx, podNamespacedName, pod)
+		Expect(apierrors.IsNotFound(err) || pod.DeletionTimestamp != nil).To(BeTrue())
+	})
+
+	It("should not consider a started worker that becomes unready as stuck", func() {
+		ready := func(status corev1.ConditionStatus) corev1.PodStatus {
+			return corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			}
+		}
+		createStuckWorker(ready(corev1.ConditionTrue))
+		createMesh(monarchv1alpha1.StartupTimeoutFail)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		pod := &corev1.Pod{}
+		Expect(k8sClient.Get(ctx, podNamespacedName, pod)).To(Succeed())
+		Expect(pod.Annotations).To(HaveKey(monarchv1alpha1.StartedAnnotation))
+
+		By("Leaving the worker alone when it becomes unready past the timeout")
+		pod.Status = ready(corev1.ConditionFalse)
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(meta.IsStatusConditionFalse(mesh.Status.Conditions, monarchv1alpha1.MeshConditionStartupBlocked)).
+			To(BeTrue())
+		Expect(mesh.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshFailed))
+	})
+})
@@ -17,12 +17,17 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
 	// +kubebuilder:scaffold:imports
@@ -106,3 +111,50 @@ func getFirstFoundEnvTestBinaryDir() string {
 	}
 	return ""
 }
+
+// deleteIfExists deletes each object stored under key, ignoring objects that do not exist.
+// envtest does not run the garbage collector, so objects owned by a MonarchMesh have to be
+// removed explicitly between specs.
+func deleteIfExists(ctx context.Context, key types.NamespacedName, objs ...client.Object) {
+	for _, obj := range objs {
+		if err := k8sClient.Get(ctx, key, obj); err == nil {
+			Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+		}
+	}
+}
+
+// newTestMesh returns a mesh stored under key running replicas workers of a single container.
+// Specs set the fields they exercise on top of it.
+func newTestMesh(key types.NamespacedName, replicas int32) *monarchv1alpha1.MonarchMesh {
+	return &monarchv1alpha1.MonarchMesh{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Spec: monarchv1alpha1.MonarchMeshSpec{
+			Replicas: replicas,
+			PodTemplate: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}},
+			},
+		},
+	}
+}
+
+// reconcileTestMesh reconciles the mesh stored under key and returns it as persisted by the
+// reconciliation.
+func reconcileTestMesh(
+	ctx context.Context, r *MonarchMeshReconciler, key types.NamespacedName,
+) *monarchv1alpha1.MonarchMesh {
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	Expect(err).NotTo(HaveOccurred())
+	mesh := &monarchv1alpha1.MonarchMesh{}
+	Expect(k8sClient.Get(ctx, key, mesh)).To(Succeed())
+	return mesh
+}
+
+// markAllReady reports all replicas of the StatefulSet stored under key as ready, since envtest
+// runs no StatefulSet controller.
+func markAllReady(ctx context.Context, key types.NamespacedName) {
+	ss := &appsv1.StatefulSet{}
+	Expect(k8sClient.Get(ctx, key, ss)).To(Succeed())
+	ss.Status.Replicas = *ss.Spec.Replicas
+	ss.Status.ReadyReplicas = *ss.Spec.Replicas
+	Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+}
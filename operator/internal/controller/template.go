@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshtemplate"
+)
+
+// templateRefField indexes meshes by the kind and name of the template they reference.
+const templateRefField = "spec.templateRef"
+
+// templateRefKey returns the templateRefField value of a template reference.
+func templateRefKey(kind, name string) string {
+	if kind == "" {
+		kind = monarchv1alpha1.MonarchMeshTemplateKind
+	}
+	return kind + "/" + name
+}
+
+// indexTemplateRef returns the referenced template of a mesh for the templateRefField index.
+func indexTemplateRef(obj client.Object) []string {
+	mesh, ok := obj.(*monarchv1alpha1.MonarchMesh)
+	if !ok || mesh.Spec.TemplateRef == nil {
+		return nil
+	}
+	return []string{templateRefKey(mesh.Spec.TemplateRef.Kind, mesh.Spec.TemplateRef.Name)}
+}
+
+// meshesForTemplate maps a MonarchMeshTemplate or ClusterMonarchMeshTemplate to the meshes
+// referencing it. A MonarchMeshTemplate is only referenced from its own namespace.
+func (r *MonarchMeshReconciler) meshesForTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	opts := []client.ListOption{}
+	kind := monarchv1alpha1.ClusterMonarchMeshTemplateKind
+	if _, ok := obj.(*monarchv1alpha1.MonarchMeshTemplate); ok {
+		kind = monarchv1alpha1.MonarchMeshTemplateKind
+		opts = append(opts, client.InNamespace(obj.GetNamespace()))
+	}
+	opts = append(opts, client.MatchingFields{templateRefField: templateRefKey(kind, obj.GetName())})
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	if err := r.List(ctx, meshes, opts...); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list meshes referencing template", "template", obj.GetName())
+		return nil
+	}
+	return meshRequests(meshes)
+}
+
+// reconcileTemplate resolves Spec.TemplateRef by merging Spec.PodTemplate over the pod template
+// of the referenced template. The merged pod template replaces Spec.PodTemplate in memory only,
+// so that the following steps create the workers from it. It returns false when the template
+// does not exist, in which case the mesh is left as is until the template is created.
+func (r *MonarchMeshReconciler) reconcileTemplate(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) (bool, error) {
+	ref := mesh.Spec.TemplateRef
+	if ref == nil {
+		mesh.Status.Template = nil
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionTemplateResolved)
+		return true, nil
+	}
+	kind := ref.Kind
+	if kind == "" {
+		kind = monarchv1alpha1.MonarchMeshTemplateKind
+	}
+
+	template, err := meshtemplate.Get(ctx, r.Client, mesh)
+	if apierrors.IsNotFound(err) {
+		message := fmt.Sprintf("%s %s does not exist", kind, ref.Name)
+		previous := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionTemplateResolved)
+		if previous == nil || previous.Status != metav1.ConditionFalse {
+			r.recordEvent(mesh, corev1.EventTypeWarning, "TemplateNotFound", message)
+		}
+		meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+			Type:    monarchv1alpha1.MeshConditionTemplateResolved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "TemplateNotFound",
+			Message: message,
+		})
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := meshtemplate.Apply(template, mesh); err != nil {
+		return false, err
+	}
+	generation := template.Generation
+
+	status := &monarchv1alpha1.TemplateStatus{Kind: kind, Name: ref.Name, Generation: generation}
+	if previous := mesh.Status.Template; previous != nil && *previous != *status {
+		r.recordEvent(mesh, corev1.EventTypeNormal, "TemplateUpdated",
+			fmt.Sprintf("Resolved generation %d of %s %s", generation, kind, ref.Name))
+	}
+	mesh.Status.Template = status
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionTemplateResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  "TemplateResolved",
+		Message: fmt.Sprintf("Resolved generation %d of %s %s", generation, kind, ref.Name),
+	})
+	return true, nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh templates", func() {
+	const resourceName = "template-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: record.NewFakeRecorder(10),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, types.NamespacedName{Name: "workers", Namespace: "default"},
+			&monarchv1alpha1.MonarchMeshTemplate{})
+		deleteIfExists(ctx, types.NamespacedName{Name: "workers"}, &monarchv1alpha1.ClusterMonarchMeshTemplate{})
+	})
+
+	templateSpec := monarchv1alpha1.MonarchMeshTemplateSpec{
+		PodTemplate: corev1.PodSpec{
+			NodeSelector: map[string]string{"accelerator": "h100"},
+			Containers: []corev1.Container{{
+				Name:  "worker",
+				Image: "monarch:0.1.0",
+				Env:   []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}, {Name: "NCCL_DEBUG", Value: "WARN"}},
+			}},
+		},
+	}
+
+	newMesh := func(ref *monarchv1alpha1.MeshTemplateReference) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 1)
+		mesh.Spec.TemplateRef = ref
+		mesh.Spec.PodTemplate.Containers = []corev1.Container{{
+			Name: "worker",
+			Env:  []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+		}}
+		return mesh
+	}
+
+	It("should merge the mesh overrides over a namespaced template", func() {
+		template := &monarchv1alpha1.MonarchMeshTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+			Spec:       templateSpec,
+		}
+		Expect(k8sClient.Create(ctx, template)).To(Succeed())
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTemplateReference{Name: "workers"}))).To(Succeed())
+
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Spec.PodTemplate.Containers[0].Image).To(BeEmpty(), "the merged template is not persisted")
+		Expect(mesh.Status.Template).To(Equal(&monarchv1alpha1.TemplateStatus{
+			Kind: monarchv1alpha1.MonarchMeshTemplateKind, Name: "workers", Generation: template.Generation,
+		}))
+		Expect(meta.IsStatusConditionTrue(mesh.Status.Conditions, monarchv1alpha1.MeshConditionTemplateResolved)).
+			To(BeTrue())
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("accelerator", "h100"))
+		worker := ss.Spec.Template.Spec.Containers[0]
+		Expect(worker.Image).To(Equal("monarch:0.1.0"))
+		Expect(worker.Env).To(ContainElements(
+			corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"},
+			corev1.EnvVar{Name: "NCCL_DEBUG", Value: "WARN"},
+		))
+
+		By("Resolving the new generation when the template changes")
+		template.Spec.PodTemplate.Containers[0].Image = "monarch:0.2.0"
+		Expect(k8sClient.Update(ctx, template)).To(Succeed())
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Template.Generation).To(Equal(template.Generation))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.Containers[0].Image).To(Equal("monarch:0.2.0"))
+	})
+
+	It("should resolve a cluster template from any namespace", func() {
+		Expect(k8sClient.Create(ctx, &monarchv1alpha1.ClusterMonarchMeshTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "workers"},
+			Spec:       templateSpec,
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTemplateReference{
+			Kind: monarchv1alpha1.ClusterMonarchMeshTemplateKind, Name: "workers",
+		}))).To(Succeed())
+
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Template.Kind).To(Equal(monarchv1alpha1.ClusterMonarchMeshTemplateKind))
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec.Containers[0].Image).To(Equal("monarch:0.1.0"))
+	})
+
+	It("should wait for a missing template without creating workers", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTemplateReference{Name: "workers"}))).To(Succeed())
+
+		mesh := reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionTemplateResolved)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("TemplateNotFound"))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, &appsv1.StatefulSet{})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,594 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/certsync"
+)
+
+// Environment variables pointing the worker and client containers at their certificate.
+//
+// Every worker pod and the client get their own kubernetes.io/tls Secret holding their certificate,
+// key and the CA bundle, issued either by the operator or by a cert-manager Certificate. All
+// workers share one pod template, which must not depend on the number of workers, so the TLS
+// sidecar reads the Secret of its pod through the API with a token only it mounts, and copies
+// its files into the volume mounted into the worker containers. No worker container can read
+// the key of another pod.
+const (
+	envPodName = "MONARCH_POD_NAME"
+	envTLSCert = "MONARCH_TLS_CERT"
+	envTLSKey  = "MONARCH_TLS_KEY"
+	envTLSCA   = "MONARCH_TLS_CA"
+
+	// caBundleKey is the key of the CA bundle in the certificate Secrets, as set by cert-manager.
+	caBundleKey = "ca.crt"
+
+	// tlsSecretSuffix is appended to the name of a pod to form the name of its certificate Secret.
+	tlsSecretSuffix = "-tls"
+
+	// certSyncContainerName is the name of the TLS sidecar of the workers.
+	certSyncContainerName = "monarch-cert-sync"
+
+	// certSyncMountPath is where the TLS sidecar mounts its API credentials.
+	certSyncMountPath = "/var/run/monarch/cert-sync"
+
+	// certSyncTokenExpiration is the lifetime of the token of the TLS sidecar, rotated by the
+	// kubelet.
+	certSyncTokenExpiration = 3600
+
+	// rootCAConfigMapName is the ConfigMap holding the CA bundle of the API server, published
+	// into every namespace.
+	rootCAConfigMapName = "kube-root-ca.crt"
+)
+
+var (
+	certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	issuerGVK      = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Issuer"}
+)
+
+// tlsSubject is the pod a certificate is issued for.
+type tlsSubject struct {
+	// secretName is the name of the Secret, and of the cert-manager Certificate, of the pod.
+	secretName string
+	commonName string
+	dnsNames   []string
+}
+
+// tlsSubjects returns the subjects of the certificates of the mesh: every worker pod, including
+// spares, for its stable DNS name, and the client.
+func tlsSubjects(mesh *monarchv1alpha1.MonarchMesh, svcName string) []tlsSubject {
+	subjects := make([]tlsSubject, 0, workerCount(mesh)+1)
+	for _, podName := range workerPodNames(mesh) {
+		subjects = append(subjects, tlsSubject{
+			secretName: podName + tlsSecretSuffix,
+			commonName: podName,
+			dnsNames:   []string{podDNSName(podName, svcName, mesh.Namespace)},
+		})
+	}
+	return append(subjects, tlsSubject{secretName: clientTLSSecretName(mesh), commonName: clientJobName(mesh)})
+}
+
+// clientTLSSecretName returns the name of the Secret holding the client certificate.
+func clientTLSSecretName(mesh *monarchv1alpha1.MonarchMesh) string {
+	return clientJobName(mesh) + tlsSecretSuffix
+}
+
+// certSyncRoleName returns the name of the Role and RoleBinding allowing the TLS sidecar of the
+// workers to read their certificate Secrets.
+func certSyncRoleName(mesh *monarchv1alpha1.MonarchMesh) string {
+	return mesh.Name + "-cert-sync"
+}
+
+// caSecretName returns the name of the Secret holding the per-mesh CA.
+func caSecretName(mesh *monarchv1alpha1.MonarchMesh) string {
+	return mesh.Name + "-ca"
+}
+
+// tlsIssuer returns the effective issuer for the mesh certificates.
+func (r *MonarchMeshReconciler) tlsIssuer(mesh *monarchv1alpha1.MonarchMesh) monarchv1alpha1.TLSIssuer {
+	if mesh.Spec.TLS.Issuer != "" {
+		return mesh.Spec.TLS.Issuer
+	}
+	if r.Config.CertManagerEnabled {
+		return monarchv1alpha1.TLSIssuerCertManager
+	}
+	return monarchv1alpha1.TLSIssuerOperator
+}
+
+// podDNSName returns the stable DNS name of a worker pod under the headless Service.
+func podDNSName(podName, svcName, namespace string) string {
+	return fmt.Sprintf("%s.%s.%s.svc", podName, svcName, namespace)
+}
+
+// workerPodNames returns the names of the StatefulSet pods for all ranks and spares of the mesh.
+func workerPodNames(mesh *monarchv1alpha1.MonarchMesh) []string {
+	names := make([]string, 0, workerCount(mesh))
+	for i := range workerCount(mesh) {
+		names = append(names, fmt.Sprintf("%s-%d", mesh.Name, i))
+	}
+	return names
+}
+
+// reconcileTLS ensures the certificates of the mesh exist and are current when Spec.TLS is set,
+// and removes them otherwise. It returns the duration after which certificates need to be renewed,
+// or zero if the controller does not need to requeue for renewal.
+func (r *MonarchMeshReconciler) reconcileTLS(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, svcName string,
+) (time.Duration, error) {
+	if mesh.Spec.TLS == nil {
+		// The CertificatesReady condition records that certificates were issued for the mesh,
+		// so meshes that never enabled TLS skip the cleanup.
+		if meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCertificatesReady) == nil {
+			return 0, nil
+		}
+		if err := r.deleteStaleCertificates(ctx, mesh, nil); err != nil {
+			return 0, err
+		}
+		for _, obj := range []client.Object{
+			&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: certSyncRoleName(mesh), Namespace: mesh.Namespace}},
+			&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: certSyncRoleName(mesh), Namespace: mesh.Namespace}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: caSecretName(mesh), Namespace: mesh.Namespace}},
+			newUnstructured(issuerGVK, caSecretName(mesh), mesh.Namespace),
+		} {
+			// cert-manager may not be installed, in which case there is nothing to delete.
+			if err := r.deleteOwned(ctx, mesh, obj); err != nil && !meta.IsNoMatchError(err) {
+				return 0, err
+			}
+		}
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionCertificatesReady)
+		return 0, nil
+	}
+
+	if err := r.reconcileCertSyncRBAC(ctx, mesh); err != nil {
+		return 0, err
+	}
+	if r.tlsIssuer(mesh) == monarchv1alpha1.TLSIssuerCertManager {
+		return 0, r.reconcileCertManagerCertificates(ctx, mesh, svcName)
+	}
+	return r.reconcileOperatorCertificates(ctx, mesh, svcName)
+}
+
+// reconcileCertSyncRBAC allows the ServiceAccount of the workers to read the certificate Secrets
+// of the workers, restricted with resourceNames, for the TLS sidecar. Only the sidecar mounts a
+// token of the ServiceAccount, unless the pod template mounts one into the worker containers.
+func (r *MonarchMeshReconciler) reconcileCertSyncRBAC(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) error {
+	meshLabels := map[string]string{r.Config.MeshLabelKey: mesh.Name}
+	secretNames := make([]string, 0, workerCount(mesh))
+	for _, podName := range workerPodNames(mesh) {
+		secretNames = append(secretNames, podName+tlsSecretSuffix)
+	}
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: certSyncRoleName(mesh), Namespace: mesh.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Labels = meshLabels
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: secretNames,
+			Verbs:         []string{"get"},
+		}}
+		return ctrl.SetControllerReference(mesh, role, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: certSyncRoleName(mesh), Namespace: mesh.Namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, binding, func() error {
+		binding.Labels = meshLabels
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name}
+		binding.Subjects = []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      workerPodServiceAccount(mesh),
+			Namespace: mesh.Namespace,
+		}}
+		return ctrl.SetControllerReference(mesh, binding, r.Scheme)
+	})
+	return err
+}
+
+// deleteStaleCertificates deletes the certificate Secrets and cert-manager Certificates of the
+// mesh whose name is not in keep, e.g. those of workers removed by scaling down. Secrets created
+// by cert-manager are not controlled by the mesh and are left to cert-manager.
+func (r *MonarchMeshReconciler) deleteStaleCertificates(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, keep map[string]bool,
+) error {
+	opts := []client.ListOption{client.InNamespace(mesh.Namespace), client.MatchingLabels{r.Config.MeshLabelKey: mesh.Name}}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, opts...); err != nil {
+		return err
+	}
+	certificates := &unstructured.UnstructuredList{}
+	certificates.SetGroupVersionKind(certificateGVK.GroupVersion().WithKind(certificateGVK.Kind + "List"))
+	// cert-manager may not be installed, in which case there is nothing to delete.
+	if err := r.List(ctx, certificates, opts...); err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+	objs := make([]client.Object, 0, len(secrets.Items)+len(certificates.Items))
+	for i := range secrets.Items {
+		objs = append(objs, &secrets.Items[i])
+	}
+	for i := range certificates.Items {
+		objs = append(objs, &certificates.Items[i])
+	}
+	for _, obj := range objs {
+		if keep[obj.GetName()] || !strings.HasSuffix(obj.GetName(), tlsSecretSuffix) || !metav1.IsControlledBy(obj, mesh) {
+			continue
+		}
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tlsDuration returns the validity period of leaf certificates.
+func (r *MonarchMeshReconciler) tlsDuration(mesh *monarchv1alpha1.MonarchMesh) time.Duration {
+	if mesh.Spec.TLS.Duration != nil && mesh.Spec.TLS.Duration.Duration > 0 {
+		return mesh.Spec.TLS.Duration.Duration
+	}
+	return r.Config.DefaultTLSDuration
+}
+
+// ensureCA returns the per-mesh CA, creating or rotating it when missing or due for renewal.
+// The CA is stored in a kubernetes.io/tls Secret so it can also back a cert-manager CA Issuer.
+func (r *MonarchMeshReconciler) ensureCA(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, now time.Time,
+) (*issuedCertificate, error) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: caSecretName(mesh), Namespace: mesh.Namespace}}
+	var ca *issuedCertificate
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		existing, err := parseCertificate(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err == nil && existing.key != nil && !needsRenewal(existing.cert, now) {
+			ca = existing
+		} else {
+			ca, err = newCACertificate(mesh.Namespace+"/"+mesh.Name, now, r.Config.CACertificateDuration)
+			if err != nil {
+				return err
+			}
+		}
+		secret.Labels = map[string]string{r.Config.MeshLabelKey: mesh.Name}
+		if secret.CreationTimestamp.IsZero() {
+			secret.Type = corev1.SecretTypeTLS
+		}
+		secret.Data = map[string][]byte{
+			corev1.TLSCertKey:       ca.certPEM,
+			corev1.TLSPrivateKeyKey: ca.keyPEM,
+			caBundleKey:             ca.certPEM,
+		}
+		return ctrl.SetControllerReference(mesh, secret, r.Scheme)
+	})
+	return ca, err
+}
+
+// reconcileOperatorCertificates issues a certificate for every worker pod and for the client,
+// each in its own Secret, signed by the per-mesh CA. Existing certificates are kept until they
+// are due for renewal or were signed by a different CA.
+func (r *MonarchMeshReconciler) reconcileOperatorCertificates(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, svcName string,
+) (time.Duration, error) {
+	now := time.Now()
+	ca, err := r.ensureCA(ctx, mesh, now)
+	if err != nil {
+		return 0, err
+	}
+	duration := r.tlsDuration(mesh)
+	nextRenewal := renewalTime(ca.cert)
+
+	subjects := tlsSubjects(mesh, svcName)
+	keep := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		keep[subject.secretName] = true
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: subject.secretName, Namespace: mesh.Namespace}}
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+			issued, err := parseCertificate(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+			if err != nil || issued.cert.CheckSignatureFrom(ca.cert) != nil || needsRenewal(issued.cert, now) {
+				issued, err = newLeafCertificate(ca, subject.commonName, subject.dnsNames, now, duration)
+				if err != nil {
+					return err
+				}
+			}
+			if t := renewalTime(issued.cert); t.Before(nextRenewal) {
+				nextRenewal = t
+			}
+			secret.Labels = map[string]string{r.Config.MeshLabelKey: mesh.Name}
+			if secret.CreationTimestamp.IsZero() {
+				secret.Type = corev1.SecretTypeTLS
+			}
+			secret.Data = map[string][]byte{
+				corev1.TLSCertKey:       issued.certPEM,
+				corev1.TLSPrivateKeyKey: issued.keyPEM,
+				caBundleKey:             ca.certPEM,
+			}
+			return ctrl.SetControllerReference(mesh, secret, r.Scheme)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := r.deleteStaleCertificates(ctx, mesh, keep); err != nil {
+		return 0, err
+	}
+
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:   monarchv1alpha1.MeshConditionCertificatesReady,
+		Status: metav1.ConditionTrue,
+		Reason: "Issued",
+		Message: fmt.Sprintf("Issued certificates for %d workers and the client, next renewal at %s",
+			workerCount(mesh), nextRenewal.UTC().Format(time.RFC3339)),
+	})
+	return max(time.Until(nextRenewal), time.Second), nil
+}
+
+// reconcileCertManagerCertificates delegates issuing to one cert-manager Certificate per worker
+// pod, whose SAN is the DNS name of the pod, and one for the client. cert-manager takes care of
+// rotation. Without an explicit IssuerRef, a CA Issuer backed by the per-mesh CA is created so
+// that certificates are still scoped to the mesh.
+func (r *MonarchMeshReconciler) reconcileCertManagerCertificates(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, svcName string,
+) error {
+	issuerRef := mesh.Spec.TLS.IssuerRef
+	if issuerRef == nil {
+		if _, err := r.ensureCA(ctx, mesh, time.Now()); err != nil {
+			return err
+		}
+		issuer := newUnstructured(issuerGVK, caSecretName(mesh), mesh.Namespace)
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, issuer, func() error {
+			if err := unstructured.SetNestedField(issuer.Object, caSecretName(mesh), "spec", "ca", "secretName"); err != nil {
+				return err
+			}
+			return ctrl.SetControllerReference(mesh, issuer, r.Scheme)
+		})
+		if meta.IsNoMatchError(err) {
+			setCertManagerUnavailable(mesh)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		issuerRef = &monarchv1alpha1.TLSIssuerReference{Name: issuer.GetName(), Kind: issuerGVK.Kind}
+	}
+	kind, group := issuerRef.Kind, issuerRef.Group
+	if kind == "" {
+		kind = issuerGVK.Kind
+	}
+	if group == "" {
+		group = issuerGVK.Group
+	}
+
+	duration := r.tlsDuration(mesh)
+	subjects := tlsSubjects(mesh, svcName)
+	keep := make(map[string]bool, len(subjects))
+	ready := 0
+	var pending string
+	for _, subject := range subjects {
+		keep[subject.secretName] = true
+		certificate := newUnstructured(certificateGVK, subject.secretName, mesh.Namespace)
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, certificate, func() error {
+			spec := map[string]interface{}{
+				"secretName":  subject.secretName,
+				"commonName":  subject.commonName,
+				"duration":    duration.String(),
+				"renewBefore": (duration / 3).String(),
+				"usages":      []interface{}{"digital signature", "key encipherment", "server auth", "client auth"},
+				"privateKey":  map[string]interface{}{"algorithm": "ECDSA", "size": int64(256), "rotationPolicy": "Always"},
+				"issuerRef":   map[string]interface{}{"name": issuerRef.Name, "kind": kind, "group": group},
+				// The operator only caches Secrets labeled with their mesh.
+				"secretTemplate": map[string]interface{}{
+					"labels": map[string]interface{}{r.Config.MeshLabelKey: mesh.Name},
+				},
+			}
+			if len(subject.dnsNames) > 0 {
+				spec["dnsNames"] = toInterfaceSlice(subject.dnsNames)
+			}
+			if err := unstructured.SetNestedMap(certificate.Object, spec, "spec"); err != nil {
+				return err
+			}
+			certificate.SetLabels(map[string]string{r.Config.MeshLabelKey: mesh.Name})
+			return ctrl.SetControllerReference(mesh, certificate, r.Scheme)
+		})
+		if meta.IsNoMatchError(err) {
+			setCertManagerUnavailable(mesh)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		isReady, message := certificateReady(certificate)
+		switch {
+		case isReady:
+			ready++
+		case pending == "":
+			pending = fmt.Sprintf("Waiting for cert-manager Certificate %s", certificate.GetName())
+			if message != "" {
+				pending += ": " + message
+			}
+		}
+	}
+	if err := r.deleteStaleCertificates(ctx, mesh, keep); err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionCertificatesReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Issued",
+		Message: fmt.Sprintf("cert-manager issued certificates for %d workers and the client", workerCount(mesh)),
+	}
+	if ready < len(subjects) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Issuing"
+		condition.Message = fmt.Sprintf("%d of %d certificates ready. %s", ready, len(subjects), pending)
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, condition)
+	return nil
+}
+
+// certificateReady returns whether the Ready condition of a cert-manager Certificate is True,
+// and the message of the condition.
+func certificateReady(certificate *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		c, ok := c.(map[string]interface{})
+		if !ok || c["type"] != "Ready" {
+			continue
+		}
+		message, _ := c["message"].(string)
+		return c["status"] == string(metav1.ConditionTrue), message
+	}
+	return false, ""
+}
+
+// setCertManagerUnavailable records that the cert-manager APIs are not installed in the cluster.
+func setCertManagerUnavailable(mesh *monarchv1alpha1.MonarchMesh) {
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionCertificatesReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "CertManagerUnavailable",
+		Message: "spec.tls.issuer is CertManager but the cert-manager.io/v1 API is not installed",
+	})
+}
+
+// tlsMountPath returns the directory the certificate of a pod is mounted at.
+func (r *MonarchMeshReconciler) tlsMountPath(mesh *monarchv1alpha1.MonarchMesh) string {
+	if mesh.Spec.TLS.MountPath != "" {
+		return mesh.Spec.TLS.MountPath
+	}
+	return r.Config.DefaultTLSMountPath
+}
+
+// injectWorkerTLS gives every worker its own certificate. The TLS sidecar reads the Secret of its
+// pod with a token projected into a volume mounted only into the sidecar, and copies its files
+// into an in-memory volume mounted into all containers of spec. The sidecar keeps the copy
+// current as certificates are rotated.
+func (r *MonarchMeshReconciler) injectWorkerTLS(spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh) {
+	mountPath := r.tlsMountPath(mesh)
+	spec.Volumes = append(spec.Volumes,
+		corev1.Volume{
+			Name: r.Config.CertSyncVolumeName,
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+					Path: certsync.TokenFile, ExpirationSeconds: ptr.To[int64](certSyncTokenExpiration),
+				}},
+				{ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: rootCAConfigMapName},
+					Items:                []corev1.KeyToPath{{Key: caBundleKey, Path: certsync.CAFile}},
+				}},
+				{DownwardAPI: &corev1.DownwardAPIProjection{Items: []corev1.DownwardAPIVolumeFile{{
+					Path: certsync.NamespaceFile, FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+				}}}},
+			}}},
+		},
+		corev1.Volume{
+			Name:         r.Config.TLSVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		},
+	)
+
+	// The sidecar is a native sidecar: it starts before the worker containers, which only start
+	// once its startup probe finds the certificate of the pod copied, and keeps running with them.
+	// $(MONARCH_POD_NAME) in the args is expanded by the kubelet from the env of the sidecar.
+	spec.InitContainers = append(spec.InitContainers, corev1.Container{
+		Name:    certSyncContainerName,
+		Image:   r.Config.CertSyncImage,
+		Command: []string{"/certsync"},
+		Args: []string{
+			"--secret=$(" + envPodName + ")" + tlsSecretSuffix,
+			"--credentials=" + certSyncMountPath,
+			"--target=" + mountPath,
+		},
+		Env:           []corev1.EnvVar{podNameEnv()},
+		Resources:     *r.Config.InjectedContainerResources.DeepCopy(),
+		RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways),
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: r.Config.CertSyncVolumeName, MountPath: certSyncMountPath, ReadOnly: true},
+			{Name: r.Config.TLSVolumeName, MountPath: mountPath},
+		},
+		StartupProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{
+				Command: []string{"/certsync", "--check", "--target=" + mountPath},
+			}},
+			PeriodSeconds:    2,
+			FailureThreshold: 30,
+		},
+	})
+	r.mountTLS(spec, mesh)
+}
+
+// injectClientTLS mounts the Secret of the client certificate into all containers of spec.
+func (r *MonarchMeshReconciler) injectClientTLS(spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh) {
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: r.Config.TLSVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: clientTLSSecretName(mesh)},
+		},
+	})
+	r.mountTLS(spec, mesh)
+}
+
+// mountTLS mounts the volume of TLSVolumeName into all containers of spec and points the
+// MONARCH_TLS_* environment variables at the certificate of the pod in it.
+func (r *MonarchMeshReconciler) mountTLS(spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh) {
+	mountPath := r.tlsMountPath(mesh)
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+			Name: r.Config.TLSVolumeName, MountPath: mountPath, ReadOnly: true,
+		})
+		c.Env = append(c.Env,
+			podNameEnv(),
+			corev1.EnvVar{Name: envTLSCert, Value: path.Join(mountPath, corev1.TLSCertKey)},
+			corev1.EnvVar{Name: envTLSKey, Value: path.Join(mountPath, corev1.TLSPrivateKeyKey)},
+			corev1.EnvVar{Name: envTLSCA, Value: path.Join(mountPath, caBundleKey)},
+		)
+	}
+}
+
+// podNameEnv returns the MONARCH_POD_NAME variable holding the name of the pod.
+func podNameEnv() corev1.EnvVar {
+	return corev1.EnvVar{Name: envPodName, ValueFrom: &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+	}}
+}
+
+// newUnstructured returns an empty object of the given kind, used for APIs such as cert-manager
+// whose Go types are not vendored by the operator.
+func newUnstructured(gvk schema.GroupVersionKind, name, namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+// toInterfaceSlice converts values for use in unstructured objects.
+func toInterfaceSlice(values []string) []interface{} {
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+	}
+	return out
+}
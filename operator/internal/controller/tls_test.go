@@ -0,0 +1,249 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh TLS", func() {
+	const resourceName = "tls-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+		svcName            string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+		svcName = resourceName + reconciler.Config.ServiceSuffix
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{Name: svcName, Namespace: "default"}, &corev1.Service{})
+		for _, name := range []string{"-0-tls", "-1-tls", "-2-tls", "-client-tls", "-ca"} {
+			deleteIfExists(ctx, types.NamespacedName{Name: resourceName + name, Namespace: "default"}, &corev1.Secret{})
+		}
+		deleteIfExists(ctx, types.NamespacedName{Name: resourceName + "-cert-sync", Namespace: "default"},
+			&rbacv1.Role{}, &rbacv1.RoleBinding{})
+	})
+
+	newMesh := func(tls *monarchv1alpha1.MeshTLS) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.TLS = tls
+		return mesh
+	}
+
+	getSecret := func(name string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, secret)).To(Succeed())
+		return secret
+	}
+
+	It("should issue a certificate Secret per pod signed by a per-mesh CA", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTLS{}))).To(Succeed())
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+		ca := getSecret(resourceName + "-ca")
+		Expect(ca.Type).To(Equal(corev1.SecretTypeTLS))
+		caCert, err := parseCertificate(ca.Data[corev1.TLSCertKey], nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caCert.cert.IsCA).To(BeTrue())
+
+		for _, pod := range []string{resourceName + "-0", resourceName + "-1"} {
+			secret := getSecret(pod + "-tls")
+			Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			// Each Secret only holds the certificate and key of its own pod.
+			Expect(secret.Data).To(HaveLen(3))
+			Expect(secret.Data).To(HaveKey("ca.crt"))
+			issued, err := parseCertificate(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issued.cert.CheckSignatureFrom(caCert.cert)).To(Succeed())
+			Expect(issued.cert.DNSNames).To(ConsistOf(pod + "." + svcName + ".default.svc"))
+		}
+		clientSecret := getSecret(resourceName + "-client-tls")
+		issued, err := parseCertificate(clientSecret.Data[corev1.TLSCertKey], clientSecret.Data[corev1.TLSPrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issued.cert.CheckSignatureFrom(caCert.cert)).To(Succeed())
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCertificatesReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(HavePrefix("Issued certificates for 2 workers and the client"))
+	})
+
+	It("should keep valid certificates across reconciles", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTLS{}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		before := getSecret(resourceName + "-0-tls")
+
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		after := getSecret(resourceName + "-0-tls")
+		Expect(after.Data[corev1.TLSCertKey]).To(Equal(before.Data[corev1.TLSCertKey]))
+	})
+
+	It("should issue certificates for spares and remove those of removed workers", func() {
+		mesh := newMesh(&monarchv1alpha1.MeshTLS{})
+		mesh.Spec.Spares = 1
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		getSecret(resourceName + "-2-tls")
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCertificatesReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Message).To(HavePrefix("Issued certificates for 3 workers and the client"))
+
+		By("Removing the spare")
+		mesh.Spec.Spares = 0
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-2-tls", Namespace: "default"},
+			&corev1.Secret{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		getSecret(resourceName + "-1-tls")
+	})
+
+	It("should only mount the certificate of its own pod into the worker containers", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTLS{MountPath: "/tls"}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		podSpec := ss.Spec.Template.Spec
+		credentials := reconciler.Config.CertSyncVolumeName
+		Expect(podSpec.Volumes).To(ContainElement(And(
+			HaveField("Name", credentials),
+			HaveField("Projected.Sources", ContainElement(HaveField("ServiceAccountToken.Path", "token"))),
+		)))
+		Expect(podSpec.Volumes).To(ContainElement(And(
+			HaveField("Name", reconciler.Config.TLSVolumeName),
+			HaveField("EmptyDir.Medium", corev1.StorageMediumMemory),
+		)))
+
+		By("Copying the certificate of the pod in a native sidecar")
+		Expect(podSpec.InitContainers).To(HaveLen(1))
+		sidecar := podSpec.InitContainers[0]
+		Expect(sidecar.Name).To(Equal(certSyncContainerName))
+		Expect(sidecar.RestartPolicy).To(HaveValue(Equal(corev1.ContainerRestartPolicyAlways)))
+		Expect(sidecar.Args).To(ContainElement("--secret=$(MONARCH_POD_NAME)-tls"))
+		Expect(sidecar.VolumeMounts).To(ContainElement(HaveField("Name", credentials)))
+
+		By("Allowing the workers to read the certificate Secrets of the mesh only")
+		role := &rbacv1.Role{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-cert-sync", Namespace: "default"},
+			role)).To(Succeed())
+		Expect(role.Rules).To(ConsistOf(HaveField("ResourceNames",
+			ConsistOf(resourceName+"-0-tls", resourceName+"-1-tls"))))
+		binding := &rbacv1.RoleBinding{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-cert-sync", Namespace: "default"},
+			binding)).To(Succeed())
+		Expect(binding.Subjects).To(ConsistOf(HaveField("Name", "default")))
+
+		container := podSpec.Containers[0]
+		Expect(container.VolumeMounts).To(ContainElement(HaveField("MountPath", "/tls")))
+		Expect(container.VolumeMounts).NotTo(ContainElement(HaveField("Name", credentials)))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: envTLSCert, Value: "/tls/tls.crt"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: envTLSCA, Value: "/tls/ca.crt"}))
+
+		By("Verifying the MonarchMesh spec was not modified")
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Spec.PodTemplate.Volumes).To(BeEmpty())
+
+		By("Keeping the pod template when scaling")
+		mesh.Spec.Replicas = 3
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.Template.Spec).To(Equal(podSpec))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(role), role)).To(Succeed())
+		Expect(role.Rules[0].ResourceNames).To(ContainElement(resourceName + "-2-tls"))
+	})
+
+	It("should report cert-manager as unavailable when its API is not installed", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTLS{
+			Issuer:    monarchv1alpha1.TLSIssuerCertManager,
+			IssuerRef: &monarchv1alpha1.TLSIssuerReference{Name: "cluster-ca", Kind: "ClusterIssuer"},
+		}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionCertificatesReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("CertManagerUnavailable"))
+	})
+
+	It("should remove the certificates when TLS is disabled", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTLS{}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		mesh.Spec.TLS = nil
+		Expect(k8sClient.Update(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		for _, name := range []string{"-0-tls", "-1-tls", "-client-tls", "-ca"} {
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + name, Namespace: "default"},
+				&corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		}
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-cert-sync", Namespace: "default"},
+			&rbacv1.Role{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(meta.FindStatusCondition(mesh.Status.Conditions,
+			monarchv1alpha1.MeshConditionCertificatesReady)).To(BeNil())
+	})
+})
+
+var _ = Describe("Certificate renewal", func() {
+	It("should renew certificates after two thirds of their lifetime", func() {
+		now := time.Now()
+		ca, err := newCACertificate("test", now, 3*time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		leaf, err := newLeafCertificate(ca, "leaf", []string{"leaf.svc"}, now, 3*time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(leaf.cert.CheckSignatureFrom(ca.cert)).To(Succeed())
+		Expect(needsRenewal(leaf.cert, now)).To(BeFalse())
+		Expect(needsRenewal(leaf.cert, now.Add(2*time.Hour))).To(BeTrue())
+	})
+})
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// injectTopology adds the affinity or spread constraints for Spec.Topology to the worker pod
+// spec. Existing affinity terms of the pod template are kept.
+func (r *MonarchMeshReconciler) injectTopology(spec *corev1.PodSpec, mesh *monarchv1alpha1.MonarchMesh) {
+	topology := mesh.Spec.Topology
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{
+		r.Config.MeshLabelKey: mesh.Name,
+		r.Config.AppLabelKey:  r.Config.AppLabelValue,
+	}}
+	required := topology.Mode == monarchv1alpha1.TopologyRequired
+
+	if topology.Packing == monarchv1alpha1.TopologySpread {
+		whenUnsatisfiable := corev1.ScheduleAnyway
+		if required {
+			whenUnsatisfiable = corev1.DoNotSchedule
+		}
+		spec.TopologySpreadConstraints = append(spec.TopologySpreadConstraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       topology.Key,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector:     selector,
+		})
+		return
+	}
+
+	// Packing uses pod affinity to the other workers of the mesh. The scheduler places the
+	// first worker freely since no pod matches the selector yet.
+	term := corev1.PodAffinityTerm{LabelSelector: selector, TopologyKey: topology.Key}
+	if spec.Affinity == nil {
+		spec.Affinity = &corev1.Affinity{}
+	}
+	if spec.Affinity.PodAffinity == nil {
+		spec.Affinity.PodAffinity = &corev1.PodAffinity{}
+	}
+	podAffinity := spec.Affinity.PodAffinity
+	if required {
+		podAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			podAffinity.RequiredDuringSchedulingIgnoredDuringExecution, term)
+		return
+	}
+	podAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		podAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{Weight: 100, PodAffinityTerm: term})
+}
+
+// reconcileTopology reports the node and, with Spec.Topology, the topology domain of the pod
+// backing each rank in Status.Ranks.
+func (r *MonarchMeshReconciler) reconcileTopology(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) error {
+	for i := range mesh.Status.Ranks {
+		rank := &mesh.Status.Ranks[i]
+		pod := &corev1.Pod{}
+		err := r.Get(ctx, types.NamespacedName{Name: rank.Pod, Namespace: mesh.Namespace}, pod)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rank.Node = pod.Spec.NodeName
+		if mesh.Spec.Topology == nil || rank.Node == "" {
+			continue
+		}
+		node := &corev1.Node{}
+		err = r.Get(ctx, types.NamespacedName{Name: rank.Node}, node)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rank.TopologyDomain = node.Labels[mesh.Spec.Topology.Key]
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh topology", func() {
+	const (
+		resourceName = "topology-test-mesh"
+		nodeName     = "topology-test-node"
+		rackLabel    = "example.com/rack"
+	)
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		deleteIfExists(ctx, types.NamespacedName{Name: resourceName + "-0", Namespace: "default"}, &corev1.Pod{})
+		deleteIfExists(ctx, types.NamespacedName{Name: nodeName}, &corev1.Node{})
+	})
+
+	newMesh := func(topology *monarchv1alpha1.MeshTopology) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.Topology = topology
+		return mesh
+	}
+
+	It("should pack workers with pod affinity by default", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTopology{Key: rackLabel}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		affinity := ss.Spec.Template.Spec.Affinity
+		Expect(affinity).NotTo(BeNil())
+		Expect(affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+		term := affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+		Expect(term.TopologyKey).To(Equal(rackLabel))
+		Expect(term.LabelSelector.MatchLabels).To(HaveKeyWithValue(reconciler.Config.MeshLabelKey, resourceName))
+	})
+
+	It("should spread workers with a required spread constraint", func() {
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTopology{
+			Key:     "topology.kubernetes.io/zone",
+			Mode:    monarchv1alpha1.TopologyRequired,
+			Packing: monarchv1alpha1.TopologySpread,
+		}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		constraints := ss.Spec.Template.Spec.TopologySpreadConstraints
+		Expect(constraints).To(HaveLen(1))
+		Expect(constraints[0].WhenUnsatisfiable).To(Equal(corev1.DoNotSchedule))
+		Expect(ss.Spec.Template.Spec.Affinity).To(BeNil())
+	})
+
+	It("should report the topology domain of each rank", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName, Labels: map[string]string{rackLabel: "rack-a"}},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-0", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:   nodeName,
+				Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}},
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, newMesh(&monarchv1alpha1.MeshTopology{Key: rackLabel}))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.Ranks).To(ConsistOf(
+			monarchv1alpha1.RankStatus{Rank: 0, Pod: resourceName + "-0", Node: nodeName, TopologyDomain: "rack-a"},
+			monarchv1alpha1.RankStatus{Rank: 1, Pod: resourceName + "-1"},
+		))
+	})
+})
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// updateRevisions copies the pod template revisions of the StatefulSet into the mesh status.
+//
+// The StatefulSet only advances its current revision for RollingUpdate, so the mesh reports
+// the update revision as current once every worker runs it.
+func updateRevisions(mesh *monarchv1alpha1.MonarchMesh, ss *appsv1.StatefulSet) {
+	mesh.Status.UpdateRevision = ss.Status.UpdateRevision
+	switch {
+	case ss.Status.Replicas > 0 && ss.Status.UpdatedReplicas == ss.Status.Replicas:
+		mesh.Status.CurrentRevision = ss.Status.UpdateRevision
+	case mesh.Status.CurrentRevision == "":
+		mesh.Status.CurrentRevision = ss.Status.CurrentRevision
+	}
+}
+
+// reconcileUpdate applies Spec.UpdateStrategy. The StatefulSet always uses the OnDelete strategy;
+// for Recreate, the controller deletes all workers that run an outdated revision at once, and
+// the StatefulSet recreates them on the new pod template.
+//
+// Workers are only compared against the update revision once the StatefulSet status observed the
+// current generation: right after the pod template changes, the status still names the previous
+// revision, and the workers already on the new one would look outdated.
+func (r *MonarchMeshReconciler) reconcileUpdate(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, ss *appsv1.StatefulSet, selectorLabels map[string]string,
+) error {
+	if mesh.Spec.UpdateStrategy == monarchv1alpha1.MeshUpdateOnDelete || ss.Status.UpdateRevision == "" ||
+		ss.Status.ObservedGeneration != ss.Generation {
+		return nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(mesh.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return err
+	}
+	outdated := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Labels[appsv1.ControllerRevisionHashLabelKey] == ss.Status.UpdateRevision {
+			continue
+		}
+		if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		outdated++
+	}
+	if outdated > 0 {
+		r.recordEvent(mesh, corev1.EventTypeNormal, "Recreating",
+			fmt.Sprintf("Deleted %d workers to recreate the mesh on revision %s", outdated, ss.Status.UpdateRevision))
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh update strategy", func() {
+	const resourceName = "update-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reconciler = &MonarchMeshReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: DefaultConfig(),
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		for _, name := range []string{resourceName + "-0", resourceName + "-1"} {
+			deleteIfExists(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &corev1.Pod{})
+		}
+	})
+
+	newMesh := func(strategy monarchv1alpha1.MeshUpdateStrategy) *monarchv1alpha1.MonarchMesh {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.UpdateStrategy = strategy
+		return mesh
+	}
+
+	// createWorker creates a worker pod as the StatefulSet controller would, since envtest
+	// does not run it.
+	createWorker := func(name, revision string) {
+		Expect(k8sClient.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{
+				reconciler.Config.MeshLabelKey:        resourceName,
+				reconciler.Config.AppLabelKey:         reconciler.Config.AppLabelValue,
+				appsv1.ControllerRevisionHashLabelKey: revision,
+			}},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "worker", Image: "monarch:latest"}}},
+		})).To(Succeed())
+	}
+
+	setRevisions := func(current, update string, updated int32) {
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		ss.Status.ObservedGeneration = ss.Generation
+		ss.Status.Replicas = 2
+		ss.Status.CurrentRevision = current
+		ss.Status.UpdateRevision = update
+		ss.Status.UpdatedReplicas = updated
+		Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+	}
+
+	It("should recreate all outdated workers at once with Recreate", func() {
+		Expect(k8sClient.Create(ctx, newMesh(""))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		Expect(ss.Spec.UpdateStrategy.Type).To(Equal(appsv1.OnDeleteStatefulSetStrategyType))
+
+		createWorker(resourceName+"-0", "rev-1")
+		createWorker(resourceName+"-1", "rev-2")
+		setRevisions("rev-1", "rev-2", 1)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-0", Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-1", Namespace: "default"},
+			&corev1.Pod{})).To(Succeed())
+
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.CurrentRevision).To(Equal("rev-1"))
+		Expect(mesh.Status.UpdateRevision).To(Equal("rev-2"))
+
+		By("Reporting the update revision as current once all workers run it")
+		setRevisions("rev-1", "rev-2", 2)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.CurrentRevision).To(Equal("rev-2"))
+	})
+
+	It("should not fail a mesh with the Workers policy when Recreate kills its workers", func() {
+		mesh := newMesh("")
+		mesh.Spec.CompletionPolicy = monarchv1alpha1.CompletionPolicyWorkers
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		// The finalizer keeps the deleted workers around as the kubelet would while it stops them.
+		const finalizer = "monarch.pytorch.org/test"
+		for _, name := range []string{resourceName + "-0", resourceName + "-1"} {
+			createWorker(name, "rev-1")
+			pod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod)).To(Succeed())
+			pod.Finalizers = []string{finalizer}
+			Expect(k8sClient.Update(ctx, pod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+				pod.Finalizers = nil
+				Expect(k8sClient.Update(ctx, pod)).To(Succeed())
+			})
+		}
+		setRevisions("rev-1", "rev-2", 0)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		By("Ignoring the exit codes of the workers stopped by the update")
+		for _, name := range []string{resourceName + "-0", resourceName + "-1"} {
+			pod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.DeletionTimestamp).NotTo(BeNil())
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "worker",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 143}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		}
+		mesh = reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(mesh.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshFailed))
+		Expect(mesh.Status.Phase).NotTo(Equal(monarchv1alpha1.MonarchMeshSucceeded))
+	})
+
+	It("should wait for the StatefulSet status to observe the current generation", func() {
+		Expect(k8sClient.Create(ctx, newMesh(""))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		createWorker(resourceName+"-0", "rev-2")
+		setRevisions("rev-1", "rev-1", 0)
+
+		By("Keeping workers on the new revision while the status names the previous one")
+		ss := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, ss)).To(Succeed())
+		ss.Status.ObservedGeneration = ss.Generation - 1
+		Expect(k8sClient.Status().Update(ctx, ss)).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-0", Namespace: "default"},
+			&corev1.Pod{})).To(Succeed())
+	})
+
+	It("should leave outdated workers in place with OnDelete", func() {
+		Expect(k8sClient.Create(ctx, newMesh(monarchv1alpha1.MeshUpdateOnDelete))).To(Succeed())
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		createWorker(resourceName+"-0", "rev-1")
+		setRevisions("rev-1", "rev-2", 0)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-0", Namespace: "default"},
+			&corev1.Pod{})).To(Succeed())
+		mesh := &monarchv1alpha1.MonarchMesh{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		Expect(mesh.Status.CurrentRevision).To(Equal("rev-1"))
+		Expect(mesh.Status.UpdateRevision).To(Equal("rev-2"))
+	})
+})
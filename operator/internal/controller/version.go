@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// workerVersion returns the Monarch version reported by a worker pod, or "" if it reports none.
+func (r *MonarchMeshReconciler) workerVersion(pod *corev1.Pod) string {
+	if version := pod.Annotations[monarchv1alpha1.VersionAnnotation]; version != "" {
+		return version
+	}
+	return pod.Labels[r.Config.VersionLabelKey]
+}
+
+// sameVersion compares Monarch versions, ignoring a leading "v".
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+// reconcileVersionSkew compares the version reported by every worker with Spec.MonarchVersion
+// and reports the result in the VersionSkew condition. A Warning event is recorded whenever the
+// set of mismatching workers changes. Workers that report no version are not flagged.
+func (r *MonarchMeshReconciler) reconcileVersionSkew(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh, selectorLabels map[string]string,
+) error {
+	if mesh.Spec.MonarchVersion == "" {
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, monarchv1alpha1.MeshConditionVersionSkew)
+		return nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(mesh.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return err
+	}
+	var mismatches []string
+	for i := range pods.Items {
+		version := r.workerVersion(&pods.Items[i])
+		if version != "" && !sameVersion(version, mesh.Spec.MonarchVersion) {
+			mismatches = append(mismatches, fmt.Sprintf("%s=%s", pods.Items[i].Name, version))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+			Type:    monarchv1alpha1.MeshConditionVersionSkew,
+			Status:  metav1.ConditionFalse,
+			Reason:  "VersionsMatch",
+			Message: fmt.Sprintf("No worker reports a version other than %s", mesh.Spec.MonarchVersion),
+		})
+		return nil
+	}
+	sort.Strings(mismatches)
+	message := fmt.Sprintf("Workers report versions other than %s: %s",
+		mesh.Spec.MonarchVersion, strings.Join(mismatches, ", "))
+	previous := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionVersionSkew)
+	if previous == nil || previous.Status != metav1.ConditionTrue || previous.Message != message {
+		r.recordEvent(mesh, corev1.EventTypeWarning, "VersionSkew", message)
+	}
+	meta.SetStatusCondition(&mesh.Status.Conditions, metav1.Condition{
+		Type:    monarchv1alpha1.MeshConditionVersionSkew,
+		Status:  metav1.ConditionTrue,
+		Reason:  "VersionMismatch",
+		Message: message,
+	})
+	return nil
+}
+
+// meshForLabeledObject maps an object carrying the mesh label, such as a worker pod or claim,
+// to the MonarchMesh it belongs to, so that changes to objects owned by the StatefulSet are
+// reconciled.
+func (r *MonarchMeshReconciler) meshForLabeledObject(_ context.Context, obj client.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[r.Config.MeshLabelKey]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}}}
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh version skew", func() {
+	const resourceName = "version-test-mesh"
+
+	var (
+		ctx                context.Context
+		reconciler         *MonarchMeshReconciler
+		recorder           *record.FakeRecorder
+		typeNamespacedName types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MonarchMeshReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Config:   DefaultConfig(),
+			Recorder: recorder,
+		}
+		typeNamespacedName = types.NamespacedName{Name: resourceName, Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		deleteIfExists(ctx, typeNamespacedName, &monarchv1alpha1.MonarchMesh{}, &appsv1.StatefulSet{})
+		deleteIfExists(ctx, types.NamespacedName{
+			Name: resourceName + reconciler.Config.ServiceSuffix, Namespace: "default",
+		}, &corev1.Service{})
+		for _, name := range []string{resourceName + "-0", resourceName + "-1"} {
+			deleteIfExists(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &corev1.Pod{})
+		}
+	})
+
+	createWorker := func(name string, labels, annotations map[string]string) {
+		labels[reconciler.Config.MeshLabelKey] = resourceName
+		labels[reconciler.Config.AppLabelKey] = reconciler.Config.AppLabelValue
+		Expect(k8sClient.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", Labels: labels, Annotations: annotations,
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "worker", Image: "monarch:0.1.0"}}},
+		})).To(Succeed())
+	}
+
+	It("should report workers running another Monarch version", func() {
+		mesh := newTestMesh(typeNamespacedName, 2)
+		mesh.Spec.MonarchVersion = "0.1.0"
+		mesh.Spec.PodTemplate.Containers[0].Image = "monarch:0.1.0"
+		Expect(k8sClient.Create(ctx, mesh)).To(Succeed())
+
+		createWorker(resourceName+"-0", map[string]string{reconciler.Config.VersionLabelKey: "v0.1.0"}, nil)
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		condition := meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionVersionSkew)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+		By("Preferring the version annotation written by the worker")
+		createWorker(resourceName+"-1", map[string]string{reconciler.Config.VersionLabelKey: "0.1.0"},
+			map[string]string{monarchv1alpha1.VersionAnnotation: "0.2.0"})
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, mesh)).To(Succeed())
+		condition = meta.FindStatusCondition(mesh.Status.Conditions, monarchv1alpha1.MeshConditionVersionSkew)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring(resourceName + "-1=0.2.0"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("VersionSkew")))
+
+		By("Recording the event only once for the same mismatch")
+		reconcileTestMesh(ctx, reconciler, typeNamespacedName)
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// claimRetentionPolicy returns Spec.PersistentVolumeClaimRetentionPolicy with the defaults of
+// the API server applied, so that the StatefulSet is not updated on every reconcile.
+func claimRetentionPolicy(mesh *monarchv1alpha1.MonarchMesh) *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy {
+	policy := &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if spec := mesh.Spec.PersistentVolumeClaimRetentionPolicy; spec != nil {
+		if spec.WhenDeleted != "" {
+			policy.WhenDeleted = spec.WhenDeleted
+		}
+		if spec.WhenScaled != "" {
+			policy.WhenScaled = spec.WhenScaled
+		}
+	}
+	return policy
+}
+
+// rankClaimName returns the name of the claim created by the StatefulSet controller from the
+// volume claim template for the pod.
+func rankClaimName(template, podName string) string {
+	return template + "-" + podName
+}
+
+// reconcileRankVolumes reports the claims of each rank created from Spec.VolumeClaimTemplates.
+// Claims are named after the pod, so a spare promoted into a rank brings its own claims.
+func (r *MonarchMeshReconciler) reconcileRankVolumes(ctx context.Context, mesh *monarchv1alpha1.MonarchMesh) error {
+	for i := range mesh.Status.Ranks {
+		rank := &mesh.Status.Ranks[i]
+		rank.Volumes = nil
+		for _, template := range mesh.Spec.VolumeClaimTemplates {
+			volume := monarchv1alpha1.RankVolume{Name: template.Name, ClaimName: rankClaimName(template.Name, rank.Pod)}
+			pvc := &corev1.PersistentVolumeClaim{}
+			err := r.Get(ctx, types.NamespacedName{Name: volume.ClaimName, Namespace: mesh.Namespace}, pvc)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			if err == nil {
+				volume.Phase, volume.VolumeName = pvc.Status.Phase, pvc.Spec.VolumeName
+			}
+			rank.Volumes = append(rank.Volumes, volume)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Package cron parses the schedules of ScheduledMonarchMeshes. It accepts the standard cron
+// format of Kubernetes CronJobs: five fields for the minute, hour, day of month, month and day
+// of week, each a list of values, ranges and steps, with names for months and days of the week,
+// or one of the @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly macros.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron schedule.
+type Schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// dayOfMonthAny and dayOfWeekAny are set when the field was * or ?. As in cron, a day
+	// matches either restricted day field when both are restricted.
+	dayOfMonthAny, dayOfWeekAny bool
+}
+
+// field describes the values accepted by a field of the schedule.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField     = field{name: "minute", min: 0, max: 59}
+	hourField       = field{name: "hour", min: 0, max: 23}
+	dayOfMonthField = field{name: "day of month", min: 1, max: 31}
+	monthField      = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7.
+	dayOfWeekField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron schedule, e.g. "0 2 * * 1-5" for 2am on weekdays.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, found %d: %q", len(fields), spec)
+	}
+
+	schedule := &Schedule{}
+	var err error
+	if schedule.minute, _, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if schedule.hour, _, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfMonth, schedule.dayOfMonthAny, err = dayOfMonthField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if schedule.month, _, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfWeek, schedule.dayOfWeekAny, err = dayOfWeekField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1 << 0
+	}
+	return schedule, nil
+}
+
+// parse returns the values of a field as a bit set, and whether it accepts any value.
+func (f field) parse(value string) (uint64, bool, error) {
+	if value == "*" || value == "?" {
+		return f.bits(f.min, f.max, 1), true, nil
+	}
+	var bits uint64
+	for _, element := range strings.Split(value, ",") {
+		rangeValue, stepValue, hasStep := strings.Cut(element, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepValue); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q in %s field", stepValue, f.name)
+			}
+		}
+
+		var low, high int
+		switch startValue, endValue, isRange := strings.Cut(rangeValue, "-"); {
+		case rangeValue == "*":
+			low, high = f.min, f.max
+		case isRange:
+			var err error
+			if low, err = f.value(startValue); err != nil {
+				return 0, false, err
+			}
+			if high, err = f.value(endValue); err != nil {
+				return 0, false, err
+			}
+		default:
+			var err error
+			if low, err = f.value(rangeValue); err != nil {
+				return 0, false, err
+			}
+			// A single value with a step runs to the end of the range, e.g. 5/15 in minutes.
+			high = low
+			if hasStep {
+				high = f.max
+			}
+		}
+		if low > high {
+			return 0, false, fmt.Errorf("invalid range %q in %s field", rangeValue, f.name)
+		}
+		bits |= f.bits(low, high, step)
+	}
+	return bits, false, nil
+}
+
+// value parses a single value of the field, by number or name.
+func (f field) value(value string) (int, error) {
+	if number, ok := f.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < f.min || number > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, expected %d-%d", value, f.name, f.min, f.max)
+	}
+	return number, nil
+}
+
+// bits returns the bit set of the values from low to high by step.
+func (f field) bits(low, high, step int) uint64 {
+	var bits uint64
+	for value := low; value <= high; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits
+}
+
+// Next returns the first time after t the schedule activates, in the location of t, or the zero
+// time if it never does, e.g. on February 30th.
+func (s *Schedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, location).Add(time.Minute)
+
+	// Schedules activate at least once in every leap cycle, unless they never do.
+	for limit := t.Year() + 5; t.Year() <= limit; {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the schedule activates on the day of t.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthAny || s.dayOfWeekAny {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
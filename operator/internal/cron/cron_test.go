@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package cron
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schedule", func() {
+	// Wednesday, January 15th 2025.
+	start := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	next := func(spec string, t time.Time) time.Time {
+		schedule, err := Parse(spec)
+		Expect(err).NotTo(HaveOccurred())
+		return schedule.Next(t)
+	}
+
+	DescribeTable("Should find the next activation",
+		func(spec string, expected time.Time) {
+			Expect(next(spec, start)).To(Equal(expected))
+		},
+		Entry("every minute", "* * * * *", time.Date(2025, time.January, 15, 10, 31, 0, 0, time.UTC)),
+		Entry("a step of minutes", "*/20 * * * *", time.Date(2025, time.January, 15, 10, 40, 0, 0, time.UTC)),
+		Entry("a step from a value", "5/20 * * * *", time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC)),
+		Entry("a list of hours", "0 9,17 * * *", time.Date(2025, time.January, 15, 17, 0, 0, 0, time.UTC)),
+		Entry("a range of weekdays", "0 2 * * mon-fri", time.Date(2025, time.January, 16, 2, 0, 0, 0, time.UTC)),
+		Entry("Sunday as 7", "0 0 * * 7", time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC)),
+		Entry("a month name", "0 0 1 Mar *", time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("a leap day", "0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)),
+		Entry("either restricted day field", "0 0 1 * fri", time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC)),
+		Entry("the @daily macro", "@daily", time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC)),
+		Entry("the @hourly macro", "@hourly", time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)),
+		Entry("the @yearly macro", "@yearly", time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	It("Should activate strictly after the given time", func() {
+		Expect(next("30 10 * * *", start)).To(Equal(time.Date(2025, time.January, 16, 10, 30, 0, 0, time.UTC)))
+		Expect(next("30 10 * * *", start.Add(-time.Second))).To(Equal(start))
+	})
+
+	It("Should activate in the location of the given time", func() {
+		location, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+		activation := next("0 2 * * *", start.In(location))
+		Expect(activation.Location()).To(Equal(location))
+		Expect(activation.UTC()).To(Equal(time.Date(2025, time.January, 16, 7, 0, 0, 0, time.UTC)))
+	})
+
+	It("Should return the zero time for schedules that never activate", func() {
+		Expect(next("0 0 30 2 *", start)).To(BeZero())
+	})
+
+	DescribeTable("Should reject invalid schedules",
+		func(spec string) {
+			_, err := Parse(spec)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("too many fields", "* * * * * *"),
+		Entry("a value out of range", "60 * * * *"),
+		Entry("an unknown name", "0 0 * * someday"),
+		Entry("a reversed range", "0 17-9 * * *"),
+		Entry("a zero step", "*/0 * * * *"),
+		Entry("an unknown macro", "@reboot"),
+	)
+})
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Package meshclass resolves, applies and validates the MonarchMeshClass of a MonarchMesh.
+// It is shared by the admission webhooks and the reconciler, so that meshes admitted while the
+// webhooks were disabled, or before their class changed, are held to the same policy.
+package meshclass
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// Name returns the class selected by mesh: Spec.ClassName, or else the class named by the
+// DefaultClassAnnotation of its namespace. It returns an empty name when the mesh has no class.
+func Name(ctx context.Context, c client.Reader, mesh *monarchv1alpha1.MonarchMesh) (string, error) {
+	if mesh.Spec.ClassName != "" {
+		return mesh.Spec.ClassName, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: mesh.Namespace}, ns); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return ns.Annotations[monarchv1alpha1.DefaultClassAnnotation], nil
+}
+
+// Allowed reports whether the namespace of mesh allows the class name: the default class of the
+// namespace, or a class listed in its AllowedClassesAnnotation. Any class is allowed in
+// namespaces without the annotation.
+func Allowed(ctx context.Context, c client.Reader, mesh *monarchv1alpha1.MonarchMesh, name string) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: mesh.Namespace}, ns); err != nil {
+		return apierrors.IsNotFound(err), client.IgnoreNotFound(err)
+	}
+	allowed, ok := ns.Annotations[monarchv1alpha1.AllowedClassesAnnotation]
+	if !ok || name == ns.Annotations[monarchv1alpha1.DefaultClassAnnotation] {
+		return true, nil
+	}
+	for _, class := range strings.Split(allowed, ",") {
+		if strings.TrimSpace(class) == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Get returns the class selected by mesh, or nil when the mesh has no class. It returns a
+// NotFound error when the selected class does not exist.
+func Get(ctx context.Context, c client.Reader, mesh *monarchv1alpha1.MonarchMesh) (*monarchv1alpha1.MonarchMeshClass, error) {
+	name, err := Name(ctx, c, mesh)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	class := &monarchv1alpha1.MonarchMeshClass{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, class); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// Apply sets the priority class, scheduler and tolerations of class on the worker pod spec.
+func Apply(class *monarchv1alpha1.MonarchMeshClass, spec *corev1.PodSpec) {
+	if class.Spec.PriorityClassName != "" {
+		spec.PriorityClassName = class.Spec.PriorityClassName
+		// The priority is resolved from the class by the Priority admission plugin.
+		spec.Priority = nil
+	}
+	if class.Spec.SchedulerName != "" {
+		spec.SchedulerName = class.Spec.SchedulerName
+	}
+	for _, toleration := range class.Spec.Tolerations {
+		matches := func(t corev1.Toleration) bool { return t.MatchToleration(&toleration) }
+		if !slices.ContainsFunc(spec.Tolerations, matches) {
+			spec.Tolerations = append(spec.Tolerations, toleration)
+		}
+	}
+}
+
+// Validate returns the violations of class by mesh. Scheduling settings left empty are not
+// violations since Apply sets them. The worker pod template is validated as given; callers
+// resolving Spec.TemplateRef pass the merged template in mesh.
+func Validate(class *monarchv1alpha1.MonarchMeshClass, mesh *monarchv1alpha1.MonarchMesh) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	if maxReplicas := class.Spec.MaxReplicas; maxReplicas != nil && mesh.Spec.Replicas > *maxReplicas {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), mesh.Spec.Replicas,
+			fmt.Sprintf("must be at most %d in MonarchMeshClass %s", *maxReplicas, class.Name)))
+	}
+	if maxPriority := class.Spec.MaxPriority; maxPriority != nil && mesh.Spec.Priority != nil &&
+		*mesh.Spec.Priority > *maxPriority {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("priority"), *mesh.Spec.Priority,
+			fmt.Sprintf("must be at most %d in MonarchMeshClass %s", *maxPriority, class.Name)))
+	}
+
+	podPath := specPath.Child("podTemplate")
+	spec := &mesh.Spec.PodTemplate
+	if class.Spec.PriorityClassName != "" && spec.PriorityClassName != "" &&
+		spec.PriorityClassName != class.Spec.PriorityClassName {
+		allErrs = append(allErrs, field.Forbidden(podPath.Child("priorityClassName"),
+			fmt.Sprintf("must be %q in MonarchMeshClass %s", class.Spec.PriorityClassName, class.Name)))
+	}
+	if class.Spec.SchedulerName != "" && spec.SchedulerName != "" &&
+		spec.SchedulerName != class.Spec.SchedulerName {
+		allErrs = append(allErrs, field.Forbidden(podPath.Child("schedulerName"),
+			fmt.Sprintf("must be %q in MonarchMeshClass %s", class.Spec.SchedulerName, class.Name)))
+	}
+
+	checkImages := func(containers []corev1.Container, path *field.Path) {
+		for i, container := range containers {
+			// Containers without an image take it from the template of Spec.TemplateRef.
+			if container.Image != "" && !ImageAllowed(class, container.Image) {
+				allErrs = append(allErrs, field.Forbidden(path.Index(i).Child("image"),
+					fmt.Sprintf("image %s is not allowed by MonarchMeshClass %s", container.Image, class.Name)))
+			}
+		}
+	}
+	checkImages(spec.InitContainers, podPath.Child("initContainers"))
+	checkImages(spec.Containers, podPath.Child("containers"))
+	if mesh.Spec.Client != nil {
+		clientPath := specPath.Child("client", "template")
+		checkImages(mesh.Spec.Client.Template.InitContainers, clientPath.Child("initContainers"))
+		checkImages(mesh.Spec.Client.Template.Containers, clientPath.Child("containers"))
+	}
+	// The barrier runs as an init container of every worker; without an override it runs the
+	// operator image.
+	if barrier := mesh.Spec.StartupBarrier; barrier != nil && barrier.Image != "" && !ImageAllowed(class, barrier.Image) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("startupBarrier", "image"),
+			fmt.Sprintf("image %s is not allowed by MonarchMeshClass %s", barrier.Image, class.Name)))
+	}
+	return allErrs
+}
+
+// ImageAllowed reports whether class allows image.
+func ImageAllowed(class *monarchv1alpha1.MonarchMeshClass, image string) bool {
+	if len(class.Spec.AllowedImages) == 0 {
+		return true
+	}
+	for _, allowed := range class.Spec.AllowedImages {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(image, prefix) {
+				return true
+			}
+		} else if image == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package meshclass
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMeshClass", func() {
+	var (
+		ctx   context.Context
+		class *monarchv1alpha1.MonarchMeshClass
+		mesh  *monarchv1alpha1.MonarchMesh
+	)
+
+	newClient := func(objs ...client.Object) client.Reader {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(monarchv1alpha1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		class = &monarchv1alpha1.MonarchMeshClass{ObjectMeta: metav1.ObjectMeta{Name: "research"}}
+		mesh = &monarchv1alpha1.MonarchMesh{
+			ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "team"},
+			Spec: monarchv1alpha1.MonarchMeshSpec{
+				Replicas: 2,
+				PodTemplate: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "worker", Image: "ghcr.io/pytorch/monarch:0.1.0"}},
+				},
+			},
+		}
+	})
+
+	Context("When resolving the class of a mesh", func() {
+		It("Should prefer Spec.ClassName over the default class of the namespace", func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "team",
+				Annotations: map[string]string{monarchv1alpha1.DefaultClassAnnotation: "research"},
+			}}
+			c := newClient(ns, class)
+			Expect(Get(ctx, c, mesh)).To(HaveField("Name", "research"))
+
+			mesh.Spec.ClassName = "batch"
+			_, err := Get(ctx, c, mesh)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should return no class without a default class", func() {
+			Expect(Get(ctx, newClient(class), mesh)).To(BeNil())
+		})
+	})
+
+	Context("When checking the classes allowed in a namespace", func() {
+		It("Should allow the default class and the listed classes", func() {
+			Expect(Allowed(ctx, newClient(), mesh, "anything")).To(BeTrue())
+
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name: "team",
+				Annotations: map[string]string{
+					monarchv1alpha1.DefaultClassAnnotation:   "research",
+					monarchv1alpha1.AllowedClassesAnnotation: "batch, interactive",
+				},
+			}}
+			c := newClient(ns)
+			Expect(Allowed(ctx, c, mesh, "research")).To(BeTrue())
+			Expect(Allowed(ctx, c, mesh, "interactive")).To(BeTrue())
+			Expect(Allowed(ctx, c, mesh, "unrestricted")).To(BeFalse())
+		})
+	})
+
+	Context("When checking images", func() {
+		It("Should match prefixes and exact images", func() {
+			Expect(ImageAllowed(class, "anything")).To(BeTrue())
+
+			class.Spec.AllowedImages = []string{"ghcr.io/pytorch/*", "docker.io/library/python:3.12"}
+			Expect(ImageAllowed(class, "ghcr.io/pytorch/monarch:0.1.0")).To(BeTrue())
+			Expect(ImageAllowed(class, "docker.io/library/python:3.12")).To(BeTrue())
+			Expect(ImageAllowed(class, "docker.io/library/python:3.13")).To(BeFalse())
+			Expect(ImageAllowed(class, "ghcr.io/other/monarch:0.1.0")).To(BeFalse())
+		})
+	})
+
+	Context("When validating a mesh", func() {
+		It("Should report images not allowed in the worker and client pods", func() {
+			class.Spec.AllowedImages = []string{"ghcr.io/pytorch/*"}
+			mesh.Spec.Client = &monarchv1alpha1.MeshClient{Template: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "client", Image: "python:3.12"}},
+			}}
+			errs := Validate(class, mesh)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.client.template.containers[0].image"))
+		})
+
+		It("Should report a priority above the maximum of the class", func() {
+			class.Spec.MaxPriority = ptr.To[int32](100)
+			mesh.Spec.Priority = ptr.To[int32](100)
+			Expect(Validate(class, mesh)).To(BeEmpty())
+
+			mesh.Spec.Priority = ptr.To[int32](1000)
+			errs := Validate(class, mesh)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.priority"))
+		})
+
+		It("Should report a startup barrier image not allowed", func() {
+			class.Spec.AllowedImages = []string{"ghcr.io/pytorch/*"}
+			mesh.Spec.StartupBarrier = &monarchv1alpha1.MeshStartupBarrier{}
+			Expect(Validate(class, mesh)).To(BeEmpty())
+
+			mesh.Spec.StartupBarrier.Image = "docker.io/library/busybox"
+			errs := Validate(class, mesh)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.startupBarrier.image"))
+		})
+
+		It("Should accept scheduling settings set by Apply", func() {
+			class.Spec.PriorityClassName = "research"
+			mesh.Spec.PodTemplate.PriorityClassName = "best-effort"
+			Expect(Validate(class, mesh)).To(HaveLen(1))
+
+			Apply(class, &mesh.Spec.PodTemplate)
+			Expect(Validate(class, mesh)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,232 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Package meshquota measures MonarchMeshes against the MonarchMeshQuotas of their namespace.
+// It is shared by the validating webhook, which rejects meshes over quota, the mesh reconciler,
+// which queues them, and the quota reconciler, which reports the usage of each quota.
+package meshquota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshtemplate"
+)
+
+// HoldsQuota reports whether mesh counts against the quotas of its namespace: it is neither
+// suspended, finished, queued nor being deleted.
+func HoldsQuota(mesh *monarchv1alpha1.MonarchMesh) bool {
+	switch mesh.Status.Phase {
+	case monarchv1alpha1.MonarchMeshSucceeded, monarchv1alpha1.MonarchMeshFailed, monarchv1alpha1.MonarchMeshQueued:
+		return false
+	}
+	return !mesh.Spec.Suspend && mesh.DeletionTimestamp == nil
+}
+
+// Demand returns the usage of mesh when it holds quota. The worker pod template of mesh must be
+// resolved against its Spec.TemplateRef, see meshtemplate.Resolve.
+func Demand(mesh *monarchv1alpha1.MonarchMesh) monarchv1alpha1.QuotaUsage {
+	workers := mesh.Spec.Replicas + mesh.Spec.Spares
+	resources := corev1.ResourceList{}
+	for name, quantity := range PodRequests(&mesh.Spec.PodTemplate) {
+		quantity.Mul(int64(workers))
+		resources[name] = quantity
+	}
+	return monarchv1alpha1.QuotaUsage{Meshes: 1, Replicas: workers, Resources: resources}
+}
+
+// PodRequests returns the requests of a pod as the scheduler computes them: the sum of the
+// requests of its containers and sidecars, i.e. init containers always restarted, or the
+// requests of an init container together with the sidecars started before it if higher, plus
+// the overhead of the pod. Resources only given a limit, as is usual for accelerators, are
+// requested at their limit.
+func PodRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for i := range spec.Containers {
+		addResources(requests, containerRequests(&spec.Containers[i]))
+	}
+	sidecars := corev1.ResourceList{}
+	initRequests := corev1.ResourceList{}
+	for i := range spec.InitContainers {
+		container := &spec.InitContainers[i]
+		running := containerRequests(container)
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResources(requests, running)
+			addResources(sidecars, running)
+			running = sidecars
+		} else {
+			addResources(running, sidecars)
+		}
+		for name, quantity := range running {
+			if current, ok := initRequests[name]; !ok || quantity.Cmp(current) > 0 {
+				initRequests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for name, quantity := range initRequests {
+		if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+			requests[name] = quantity
+		}
+	}
+	addResources(requests, spec.Overhead)
+	return requests
+}
+
+// containerRequests returns the requests of a container, defaulted to its limits.
+func containerRequests(container *corev1.Container) corev1.ResourceList {
+	requests := container.Resources.Requests.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	for name, quantity := range container.Resources.Limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = quantity.DeepCopy()
+		}
+	}
+	return requests
+}
+
+// addResources adds the quantities of b to a.
+func addResources(a, b corev1.ResourceList) {
+	for name, quantity := range b {
+		if current, ok := a[name]; ok {
+			current.Add(quantity)
+			a[name] = current
+		} else {
+			a[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// Add returns the sum of two usages.
+func Add(a, b monarchv1alpha1.QuotaUsage) monarchv1alpha1.QuotaUsage {
+	sum := monarchv1alpha1.QuotaUsage{
+		Meshes:    a.Meshes + b.Meshes,
+		Replicas:  a.Replicas + b.Replicas,
+		Resources: corev1.ResourceList{},
+	}
+	addResources(sum.Resources, a.Resources)
+	addResources(sum.Resources, b.Resources)
+	return sum
+}
+
+// Grows reports whether to uses more than from of anything.
+func Grows(from, to monarchv1alpha1.QuotaUsage) bool {
+	if to.Meshes > from.Meshes || to.Replicas > from.Replicas {
+		return true
+	}
+	for name, quantity := range to.Resources {
+		if previous, ok := from.Resources[name]; !ok || quantity.Cmp(previous) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Used returns the usage of the meshes holding quota, leaving out the mesh named skip.
+func Used(meshes []monarchv1alpha1.MonarchMesh, skip string) monarchv1alpha1.QuotaUsage {
+	used := monarchv1alpha1.QuotaUsage{Resources: corev1.ResourceList{}}
+	for i := range meshes {
+		if meshes[i].Name == skip || !HoldsQuota(&meshes[i]) {
+			continue
+		}
+		used = Add(used, Demand(&meshes[i]))
+	}
+	return used
+}
+
+// Limited returns the usage restricted to the resources limited by quota.
+func Limited(quota *monarchv1alpha1.MonarchMeshQuota, usage monarchv1alpha1.QuotaUsage) monarchv1alpha1.QuotaUsage {
+	limited := usage
+	limited.Resources = corev1.ResourceList{}
+	for name := range quota.Spec.Resources {
+		quantity, ok := usage.Resources[name]
+		if !ok {
+			quantity = resource.MustParse("0")
+		}
+		limited.Resources[name] = quantity
+	}
+	return limited
+}
+
+// Exceeded describes the limits of quota exceeded by usage, e.g. "replicas: 72 > 64".
+func Exceeded(quota *monarchv1alpha1.MonarchMeshQuota, usage monarchv1alpha1.QuotaUsage) []string {
+	var exceeded []string
+	if limit := quota.Spec.Meshes; limit != nil && usage.Meshes > *limit {
+		exceeded = append(exceeded, fmt.Sprintf("meshes: %d > %d", usage.Meshes, *limit))
+	}
+	if limit := quota.Spec.Replicas; limit != nil && usage.Replicas > *limit {
+		exceeded = append(exceeded, fmt.Sprintf("replicas: %d > %d", usage.Replicas, *limit))
+	}
+	names := make([]string, 0, len(quota.Spec.Resources))
+	for name := range quota.Spec.Resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		limit := quota.Spec.Resources[corev1.ResourceName(name)]
+		if used, ok := usage.Resources[corev1.ResourceName(name)]; ok && used.Cmp(limit) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s: %s > %s", name, used.String(), limit.String()))
+		}
+	}
+	return exceeded
+}
+
+// Violation is a quota that a mesh would exceed.
+type Violation struct {
+	Quota    *monarchv1alpha1.MonarchMeshQuota
+	Exceeded []string
+}
+
+// String describes the violation, e.g. "MonarchMeshQuota team (replicas: 72 > 64)".
+func (v Violation) String() string {
+	return fmt.Sprintf("MonarchMeshQuota %s (%s)", v.Quota.Name, strings.Join(v.Exceeded, ", "))
+}
+
+// Check returns the quotas of the namespace of mesh that it would exceed on top of the other
+// meshes holding quota. The worker pod templates of the meshes are resolved against their
+// Spec.TemplateRef, so that the requests of workers taken from a template count.
+func Check(ctx context.Context, c client.Reader, mesh *monarchv1alpha1.MonarchMesh) ([]Violation, error) {
+	quotas := &monarchv1alpha1.MonarchMeshQuotaList{}
+	if err := c.List(ctx, quotas, client.InNamespace(mesh.Namespace)); err != nil {
+		return nil, err
+	}
+	if len(quotas.Items) == 0 {
+		return nil, nil
+	}
+	meshes := &monarchv1alpha1.MonarchMeshList{}
+	if err := c.List(ctx, meshes, client.InNamespace(mesh.Namespace)); err != nil {
+		return nil, err
+	}
+	if err := meshtemplate.ResolveAll(ctx, c, meshes.Items); err != nil {
+		return nil, err
+	}
+	mesh = mesh.DeepCopy()
+	if err := meshtemplate.Resolve(ctx, c, mesh); err != nil {
+		return nil, err
+	}
+	return Violations(quotas.Items, Add(Used(meshes.Items, mesh.Name), Demand(mesh))), nil
+}
+
+// Violations returns the quotas exceeded by usage.
+func Violations(quotas []monarchv1alpha1.MonarchMeshQuota, usage monarchv1alpha1.QuotaUsage) []Violation {
+	var violations []Violation
+	for i := range quotas {
+		if exceeded := Exceeded(&quotas[i], usage); len(exceeded) > 0 {
+			violations = append(violations, Violation{Quota: &quotas[i], Exceeded: exceeded})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package meshquota
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMeshQuota", func() {
+	const gpu = corev1.ResourceName("nvidia.com/gpu")
+
+	var (
+		ctx   context.Context
+		quota *monarchv1alpha1.MonarchMeshQuota
+	)
+
+	newClient := func(objs ...client.Object) client.Reader {
+		scheme := runtime.NewScheme()
+		Expect(monarchv1alpha1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	newMesh := func(name string, replicas int32, gpus string) *monarchv1alpha1.MonarchMesh {
+		return &monarchv1alpha1.MonarchMesh{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team"},
+			Spec: monarchv1alpha1.MonarchMeshSpec{
+				Replicas: replicas,
+				PodTemplate: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "worker",
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{gpu: resource.MustParse(gpus)},
+						},
+					}},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		quota = &monarchv1alpha1.MonarchMeshQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "team"},
+			Spec: monarchv1alpha1.MonarchMeshQuotaSpec{
+				Meshes:    ptr.To[int32](2),
+				Replicas:  ptr.To[int32](8),
+				Resources: corev1.ResourceList{gpu: resource.MustParse("32")},
+			},
+		}
+	})
+
+	Context("When computing the requests of a pod", func() {
+		It("Should sum the containers and default requests to limits", func() {
+			spec := &corev1.PodSpec{
+				InitContainers: []corev1.Container{{Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+				}}},
+				Containers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						Limits:   corev1.ResourceList{gpu: resource.MustParse("8")},
+					}},
+					{Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+					}},
+				},
+			}
+			requests := PodRequests(spec)
+			Expect(requests.Cpu().Equal(resource.MustParse("4"))).To(BeTrue())
+			Expect(requests.Name(gpu, resource.DecimalSI).Equal(resource.MustParse("8"))).To(BeTrue())
+		})
+
+		It("Should add sidecars to the containers and to the init containers started after them", func() {
+			cpu := func(quantity string) corev1.ResourceRequirements {
+				return corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(quantity)}}
+			}
+			spec := &corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Resources: cpu("1"), RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways)},
+					{Resources: cpu("3")},
+				},
+				Containers: []corev1.Container{{Resources: cpu("2")}},
+				Overhead:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			}
+			requests := PodRequests(spec)
+			Expect(requests.Cpu().Equal(resource.MustParse("4100m"))).To(BeTrue())
+
+			spec.InitContainers[1].Resources = cpu("1")
+			requests = PodRequests(spec)
+			Expect(requests.Cpu().Equal(resource.MustParse("3100m"))).To(BeTrue())
+		})
+	})
+
+	Context("When checking a mesh against the quotas of its namespace", func() {
+		It("Should count the workers and accelerators of the meshes holding quota", func() {
+			running := newMesh("running", 4, "4")
+			running.Spec.Spares = 1
+			suspended := newMesh("suspended", 8, "8")
+			suspended.Spec.Suspend = true
+			c := newClient(quota, running, suspended)
+
+			Expect(Check(ctx, c, newMesh("mesh", 3, "4"))).To(BeEmpty())
+
+			violations, err := Check(ctx, c, newMesh("mesh", 4, "8"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].String()).To(Equal(
+				"MonarchMeshQuota team (replicas: 9 > 8, nvidia.com/gpu: 52 > 32)"))
+		})
+
+		It("Should count the requests of the workers taken from a template", func() {
+			template := &monarchv1alpha1.MonarchMeshTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "h100", Namespace: "team"},
+				Spec: monarchv1alpha1.MonarchMeshTemplateSpec{
+					PodTemplate: newMesh("", 0, "8").Spec.PodTemplate,
+				},
+			}
+			fromTemplate := func(name string, replicas int32) *monarchv1alpha1.MonarchMesh {
+				mesh := newMesh(name, replicas, "0")
+				mesh.Spec.TemplateRef = &monarchv1alpha1.MeshTemplateReference{Name: template.Name}
+				mesh.Spec.PodTemplate.Containers[0].Resources = corev1.ResourceRequirements{}
+				return mesh
+			}
+			c := newClient(quota, template, fromTemplate("running", 2))
+
+			violations, err := Check(ctx, c, fromTemplate("mesh", 3))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].String()).To(Equal("MonarchMeshQuota team (nvidia.com/gpu: 40 > 32)"))
+		})
+
+		It("Should not count the mesh being checked twice", func() {
+			mesh := newMesh("mesh", 8, "4")
+			Expect(Check(ctx, newClient(quota, mesh), mesh)).To(BeEmpty())
+		})
+	})
+
+	Context("When reporting usage", func() {
+		It("Should only report the resources limited by the quota", func() {
+			quota.Spec.Resources = corev1.ResourceList{"example.com/tpu": resource.MustParse("4")}
+			usage := Limited(quota, Used([]monarchv1alpha1.MonarchMesh{*newMesh("mesh", 2, "8")}, ""))
+			Expect(usage.Meshes).To(Equal(int32(1)))
+			Expect(usage.Replicas).To(Equal(int32(2)))
+			Expect(usage.Resources).To(HaveLen(1))
+			Expect(usage.Resources.Name("example.com/tpu", resource.DecimalSI).IsZero()).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+// Package meshtemplate resolves the worker pod template of a MonarchMesh against the template
+// referenced by its Spec.TemplateRef. It is shared by the reconciler, which creates the workers
+// from the resolved template, and by the quota and capacity checks, so that the requests of
+// workers taken from a template count.
+package meshtemplate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+// Template is a template referenced by Spec.TemplateRef.
+type Template struct {
+	// Kind is the kind of the template, MonarchMeshTemplate or ClusterMonarchMeshTemplate.
+	Kind string
+	// Name is the name of the template.
+	Name string
+	// Generation is the generation of the template.
+	Generation int64
+	// Spec is the spec of the template.
+	Spec *monarchv1alpha1.MonarchMeshTemplateSpec
+}
+
+// Get returns the template referenced by mesh, or nil when the mesh references none. It returns
+// a NotFound error when the template does not exist.
+func Get(ctx context.Context, c client.Reader, mesh *monarchv1alpha1.MonarchMesh) (*Template, error) {
+	ref := mesh.Spec.TemplateRef
+	if ref == nil {
+		return nil, nil
+	}
+	template := &Template{Kind: ref.Kind, Name: ref.Name}
+	if template.Kind == "" {
+		template.Kind = monarchv1alpha1.MonarchMeshTemplateKind
+	}
+	switch template.Kind {
+	case monarchv1alpha1.ClusterMonarchMeshTemplateKind:
+		obj := &monarchv1alpha1.ClusterMonarchMeshTemplate{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, obj); err != nil {
+			return nil, err
+		}
+		template.Generation, template.Spec = obj.Generation, &obj.Spec
+	default:
+		obj := &monarchv1alpha1.MonarchMeshTemplate{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: mesh.Namespace}, obj); err != nil {
+			return nil, err
+		}
+		template.Generation, template.Spec = obj.Generation, &obj.Spec
+	}
+	return template, nil
+}
+
+// Apply merges the worker pod template of mesh over the pod template of template, and replaces
+// the worker pod template of mesh with the result.
+func Apply(template *Template, mesh *monarchv1alpha1.MonarchMesh) error {
+	merged, err := Merge(&template.Spec.PodTemplate, &mesh.Spec.PodTemplate)
+	if err != nil {
+		return fmt.Errorf("merging pod template over %s %s: %w", template.Kind, template.Name, err)
+	}
+	mesh.Spec.PodTemplate = *merged
+	return nil
+}
+
+// Resolve replaces the worker pod template of mesh with its template resolved against
+// Spec.TemplateRef, in memory. Meshes referencing a missing template are left as they are, since
+// they wait for it without workers.
+func Resolve(ctx context.Context, c client.Reader, mesh *monarchv1alpha1.MonarchMesh) error {
+	template, err := Get(ctx, c, mesh)
+	if apierrors.IsNotFound(err) || (err == nil && template == nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return Apply(template, mesh)
+}
+
+// ResolveAll resolves the worker pod template of each mesh, see Resolve.
+func ResolveAll(ctx context.Context, c client.Reader, meshes []monarchv1alpha1.MonarchMesh) error {
+	for i := range meshes {
+		if err := Resolve(ctx, c, &meshes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge merges overrides over base with strategic merge patch semantics, so that list entries
+// such as containers, volumes and env vars are merged by name.
+func Merge(base, overrides *corev1.PodSpec) (*corev1.PodSpec, error) {
+	original, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	// Containers is never omitted, and a null list would delete the containers of base.
+	overrides = overrides.DeepCopy()
+	if overrides.Containers == nil {
+		overrides.Containers = []corev1.Container{}
+	}
+	patch, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, err
+	}
+	data, err := strategicpatch.StrategicMergePatch(original, patch, corev1.PodSpec{})
+	if err != nil {
+		return nil, err
+	}
+	merged := &corev1.PodSpec{}
+	if err := json.Unmarshal(data, merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
@@ -0,0 +1,303 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshclass"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshquota"
+	"github.com/meta-pytorch/monarch-kubernetes/internal/meshtemplate"
+)
+
+// log is for logging in this package.
+var monarchmeshlog = logf.Log.WithName("monarchmesh-resource")
+
+// SetupMonarchMeshWebhookWithManager registers the webhook for MonarchMesh in the manager.
+// enforceImageVersions enables the check of image tags against Spec.MonarchVersion.
+func SetupMonarchMeshWebhookWithManager(mgr ctrl.Manager, enforceImageVersions bool) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&monarchv1alpha1.MonarchMesh{}).
+		WithDefaulter(&MonarchMeshCustomDefaulter{Client: mgr.GetClient()}).
+		WithValidator(&MonarchMeshCustomValidator{Client: mgr.GetClient(), EnforceImageVersions: enforceImageVersions}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-monarch-pytorch-org-v1alpha1-monarchmesh,mutating=true,failurePolicy=fail,sideEffects=None,groups=monarch.pytorch.org,resources=monarchmeshes,verbs=create;update,versions=v1alpha1,name=mmonarchmesh-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// MonarchMeshCustomDefaulter struct is responsible for setting default values on the MonarchMesh
+// resource when it is created or updated.
+//
+// It applies the scheduling settings of the MonarchMeshClass selected by Spec.ClassName to the
+// worker pod template, so that users see the settings in effect. The default class of the
+// namespace is left to the reconciler, so that changing the default reaches existing meshes.
+type MonarchMeshCustomDefaulter struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomDefaulter = &MonarchMeshCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type MonarchMesh.
+func (d *MonarchMeshCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	mesh, ok := obj.(*monarchv1alpha1.MonarchMesh)
+	if !ok {
+		return fmt.Errorf("expected a MonarchMesh object but got %T", obj)
+	}
+	monarchmeshlog.Info("Defaulting for MonarchMesh", "name", mesh.GetName())
+
+	if mesh.Spec.ClassName == "" {
+		return nil
+	}
+	class := &monarchv1alpha1.MonarchMeshClass{}
+	err := d.Client.Get(ctx, types.NamespacedName{Name: mesh.Spec.ClassName}, class)
+	if apierrors.IsNotFound(err) {
+		// The validating webhook rejects meshes of a missing class.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	meshclass.Apply(class, &mesh.Spec.PodTemplate)
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-monarch-pytorch-org-v1alpha1-monarchmesh,mutating=false,failurePolicy=fail,sideEffects=None,groups=monarch.pytorch.org,resources=monarchmeshes,verbs=create;update,versions=v1alpha1,name=vmonarchmesh-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// MonarchMeshCustomValidator struct is responsible for validating the MonarchMesh resource
+// when it is created, updated, or deleted.
+//
+// The webhook is optional: it is only served when the operator runs with --enable-webhooks.
+type MonarchMeshCustomValidator struct {
+	// Client reads the MonarchMeshClass of the mesh and the default class of its namespace.
+	Client client.Reader
+
+	// EnforceImageVersions rejects meshes whose Monarch container image is not tagged with
+	// Spec.MonarchVersion. Set by --enforce-image-versions.
+	EnforceImageVersions bool
+}
+
+var _ webhook.CustomValidator = &MonarchMeshCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type MonarchMesh.
+func (v *MonarchMeshCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	mesh, ok := obj.(*monarchv1alpha1.MonarchMesh)
+	if !ok {
+		return nil, fmt.Errorf("expected a MonarchMesh object but got %T", obj)
+	}
+	monarchmeshlog.Info("Validation for MonarchMesh upon creation", "name", mesh.GetName())
+
+	specErrs, err := v.validateSpec(ctx, mesh)
+	if err != nil {
+		return nil, err
+	}
+	quotaErrs, err := v.validateQuota(ctx, nil, mesh)
+	if err != nil {
+		return nil, err
+	}
+	return nil, v.validateMonarchMesh(mesh, append(specErrs, quotaErrs...)...)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type MonarchMesh.
+func (v *MonarchMeshCustomValidator) ValidateUpdate(
+	ctx context.Context, oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	oldMesh, ok := oldObj.(*monarchv1alpha1.MonarchMesh)
+	if !ok {
+		return nil, fmt.Errorf("expected a MonarchMesh object for the oldObj but got %T", oldObj)
+	}
+	mesh, ok := newObj.(*monarchv1alpha1.MonarchMesh)
+	if !ok {
+		return nil, fmt.Errorf("expected a MonarchMesh object for the newObj but got %T", newObj)
+	}
+	monarchmeshlog.Info("Validation for MonarchMesh upon update", "name", mesh.GetName())
+
+	allErrs := validateImmutableFields(oldMesh, mesh)
+	// Meshes admitted before their class was tightened or the image versions were enforced can
+	// still be updated, e.g. to be annotated or suspended by the idle timeout, as long as the
+	// rest of the spec doesn't change.
+	if specChanged(oldMesh, mesh) {
+		specErrs, err := v.validateSpec(ctx, mesh)
+		if err != nil {
+			return nil, err
+		}
+		allErrs = append(allErrs, specErrs...)
+	}
+	quotaErrs, err := v.validateQuota(ctx, oldMesh, mesh)
+	if err != nil {
+		return nil, err
+	}
+	allErrs = append(allErrs, quotaErrs...)
+	return nil, v.validateMonarchMesh(mesh, allErrs...)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type MonarchMesh.
+func (v *MonarchMeshCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateMonarchMesh returns an Invalid error listing the violations found in mesh, or nil.
+func (v *MonarchMeshCustomValidator) validateMonarchMesh(
+	mesh *monarchv1alpha1.MonarchMesh, allErrs ...*field.Error,
+) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(monarchv1alpha1.GroupVersion.WithKind("MonarchMesh").GroupKind(), mesh.Name, allErrs)
+}
+
+// validateSpec checks the spec of the mesh against its MonarchMeshClass and, when enforced,
+// the Monarch version.
+func (v *MonarchMeshCustomValidator) validateSpec(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) (field.ErrorList, error) {
+	allErrs, err := v.validateClass(ctx, mesh)
+	if err != nil {
+		return nil, err
+	}
+	if v.EnforceImageVersions {
+		allErrs = append(allErrs, validateImageVersions(mesh)...)
+	}
+	return allErrs, nil
+}
+
+// specChanged reports whether an update changes the spec of the mesh other than Spec.Suspend.
+func specChanged(oldMesh, mesh *monarchv1alpha1.MonarchMesh) bool {
+	oldSpec, spec := oldMesh.Spec, mesh.Spec
+	oldSpec.Suspend, spec.Suspend = false, false
+	return !apiequality.Semantic.DeepEqual(oldSpec, spec)
+}
+
+// validateClass checks that the namespace allows the MonarchMeshClass of the mesh, and the mesh
+// against the limits of the class. Worker images taken from the template of Spec.TemplateRef are
+// only checked by the reconciler.
+func (v *MonarchMeshCustomValidator) validateClass(
+	ctx context.Context, mesh *monarchv1alpha1.MonarchMesh,
+) (field.ErrorList, error) {
+	if mesh.Spec.ClassName != "" {
+		allowed, err := meshclass.Allowed(ctx, v.Client, mesh, mesh.Spec.ClassName)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return field.ErrorList{field.Forbidden(field.NewPath("spec", "className"),
+				fmt.Sprintf("MonarchMeshClass %s is not allowed in namespace %s", mesh.Spec.ClassName, mesh.Namespace))}, nil
+		}
+	}
+	class, err := meshclass.Get(ctx, v.Client, mesh)
+	if apierrors.IsNotFound(err) {
+		name, _ := meshclass.Name(ctx, v.Client, mesh)
+		return field.ErrorList{field.NotFound(field.NewPath("spec", "className"), name)}, nil
+	}
+	if err != nil || class == nil {
+		return nil, err
+	}
+	return meshclass.Validate(class, mesh), nil
+}
+
+// validateQuota rejects meshes that would exceed a MonarchMeshQuota of their namespace with the
+// Reject action. Updates are only checked when they increase the usage of the mesh, e.g. when it
+// is resumed or scaled up, so that meshes over a lowered quota can still be scaled down. The
+// usage of workers taken from the template of Spec.TemplateRef counts. The containers the
+// operator injects into the workers are only counted by the reconciler, which queues the meshes
+// they take over quota.
+func (v *MonarchMeshCustomValidator) validateQuota(
+	ctx context.Context, oldMesh, mesh *monarchv1alpha1.MonarchMesh,
+) (field.ErrorList, error) {
+	if !meshquota.HoldsQuota(mesh) {
+		return nil, nil
+	}
+	if oldMesh != nil && meshquota.HoldsQuota(oldMesh) {
+		oldResolved, resolved := oldMesh.DeepCopy(), mesh.DeepCopy()
+		if err := meshtemplate.Resolve(ctx, v.Client, oldResolved); err != nil {
+			return nil, err
+		}
+		if err := meshtemplate.Resolve(ctx, v.Client, resolved); err != nil {
+			return nil, err
+		}
+		if !meshquota.Grows(meshquota.Demand(oldResolved), meshquota.Demand(resolved)) {
+			return nil, nil
+		}
+	}
+	violations, err := meshquota.Check(ctx, v.Client, mesh)
+	if err != nil {
+		return nil, err
+	}
+	var allErrs field.ErrorList
+	for _, violation := range violations {
+		if violation.Quota.Spec.OverQuotaAction == monarchv1alpha1.OverQuotaQueue {
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "exceeded "+violation.String()))
+	}
+	return allErrs, nil
+}
+
+// validateImmutableFields rejects changes to fields that cannot be applied to an existing mesh.
+func validateImmutableFields(oldMesh, mesh *monarchv1alpha1.MonarchMesh) field.ErrorList {
+	var allErrs field.ErrorList
+	if !apiequality.Semantic.DeepEqual(oldMesh.Spec.VolumeClaimTemplates, mesh.Spec.VolumeClaimTemplates) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "volumeClaimTemplates"),
+			"volume claim templates cannot be changed once the mesh has been created"))
+	}
+	return allErrs
+}
+
+// validateImageVersions rejects Monarch containers whose image tag does not match
+// Spec.MonarchVersion, since the controller and worker protocol is not compatible across versions.
+// The Monarch container is the first container of the worker and client templates; sidecars
+// such as log shippers or proxies are versioned independently and not checked.
+func validateImageVersions(mesh *monarchv1alpha1.MonarchMesh) field.ErrorList {
+	if mesh.Spec.MonarchVersion == "" {
+		return nil
+	}
+	var allErrs field.ErrorList
+	check := func(containers []corev1.Container, path *field.Path, templated bool) {
+		// Containers without an image take it from the referenced template.
+		if len(containers) == 0 || templated && containers[0].Image == "" {
+			return
+		}
+		if image := containers[0].Image; !imageMatchesVersion(image, mesh.Spec.MonarchVersion) {
+			allErrs = append(allErrs, field.Invalid(path.Index(0).Child("image"), image,
+				fmt.Sprintf("image tag must match spec.monarchVersion %q", mesh.Spec.MonarchVersion)))
+		}
+	}
+	check(mesh.Spec.PodTemplate.Containers, field.NewPath("spec", "podTemplate", "containers"),
+		mesh.Spec.TemplateRef != nil)
+	if mesh.Spec.Client != nil {
+		check(mesh.Spec.Client.Template.Containers, field.NewPath("spec", "client", "template", "containers"), false)
+	}
+	return allErrs
+}
+
+// imageMatchesVersion reports whether the tag of image is version, optionally prefixed with "v"
+// and suffixed with a variant such as "-cuda12". Images without a tag never match.
+func imageMatchesVersion(image, version string) bool {
+	image, _, _ = strings.Cut(image, "@")
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	if !ok {
+		return false
+	}
+	tag, version = strings.TrimPrefix(tag, "v"), strings.TrimPrefix(version, "v")
+	return tag == version || strings.HasPrefix(tag, version+"-")
+}
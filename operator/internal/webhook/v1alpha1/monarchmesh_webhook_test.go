@@ -0,0 +1,284 @@
+/*
+ * Copyright (c) Meta Platforms, Inc. and affiliates.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monarchv1alpha1 "github.com/meta-pytorch/monarch-kubernetes/api/v1alpha1"
+)
+
+var _ = Describe("MonarchMesh Webhook", func() {
+	var (
+		ctx       context.Context
+		obj       *monarchv1alpha1.MonarchMesh
+		validator MonarchMeshCustomValidator
+		defaulter MonarchMeshCustomDefaulter
+	)
+
+	// newClient returns a client serving objs, standing in for the API server.
+	newClient := func(objs ...client.Object) client.Reader {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(monarchv1alpha1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &monarchv1alpha1.MonarchMesh{
+			ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "default"},
+			Spec: monarchv1alpha1.MonarchMeshSpec{
+				Replicas: 2,
+				PodTemplate: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "worker", Image: "ghcr.io/pytorch/monarch:0.1.0"}},
+				},
+			},
+		}
+		validator = MonarchMeshCustomValidator{Client: newClient()}
+		defaulter = MonarchMeshCustomDefaulter{Client: validator.Client}
+	})
+
+	Context("When validating the Monarch version", func() {
+		BeforeEach(func() {
+			validator.EnforceImageVersions = true
+		})
+
+		It("Should admit any image without a declared version", func() {
+			obj.Spec.PodTemplate.Containers[0].Image = "monarch"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit images tagged with the declared version", func() {
+			obj.Spec.MonarchVersion = "v0.1.0"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.PodTemplate.Containers[0].Image = "registry:5000/monarch:0.1.0-cuda12@sha256:abc"
+			Expect(validator.ValidateUpdate(ctx, obj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should reject worker and client images with another tag", func() {
+			obj.Spec.MonarchVersion = "0.2.0"
+			obj.Spec.Client = &monarchv1alpha1.MeshClient{Template: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "client", Image: "registry:5000/monarch"}},
+			}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.podTemplate.containers[0].image"))
+			Expect(err.Error()).To(ContainSubstring("spec.client.template.containers[0].image"))
+		})
+
+		It("Should only check the Monarch container", func() {
+			obj.Spec.MonarchVersion = "0.1.0"
+			obj.Spec.PodTemplate.Containers = append(obj.Spec.PodTemplate.Containers,
+				corev1.Container{Name: "log-shipper", Image: "fluent/fluent-bit:3.1"})
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit suspending a mesh created before the versions were enforced", func() {
+			obj.Spec.MonarchVersion = "0.2.0"
+			updated := obj.DeepCopy()
+			updated.Spec.Suspend = true
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().NotTo(HaveOccurred())
+
+			updated.Spec.Replicas = 4
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().To(HaveOccurred())
+		})
+
+		It("Should not check images unless enforced", func() {
+			obj.Spec.MonarchVersion = "0.2.0"
+			validator.EnforceImageVersions = false
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit worker containers taking their image from the referenced template", func() {
+			obj.Spec.MonarchVersion = "0.2.0"
+			obj.Spec.PodTemplate.Containers[0].Image = ""
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(HaveOccurred())
+
+			obj.Spec.TemplateRef = &monarchv1alpha1.MeshTemplateReference{Name: "workers"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When updating a mesh", func() {
+		It("Should reject changes to the volume claim templates", func() {
+			updated := obj.DeepCopy()
+			updated.Spec.Replicas = 4
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().NotTo(HaveOccurred())
+
+			updated.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}},
+			}
+			_, err := validator.ValidateUpdate(ctx, obj, updated)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.volumeClaimTemplates"))
+		})
+	})
+
+	Context("When the mesh has a MonarchMeshClass", func() {
+		var class *monarchv1alpha1.MonarchMeshClass
+
+		BeforeEach(func() {
+			class = &monarchv1alpha1.MonarchMeshClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "research"},
+				Spec: monarchv1alpha1.MonarchMeshClassSpec{
+					PriorityClassName: "research",
+					SchedulerName:     "gang-scheduler",
+					Tolerations:       []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+					MaxReplicas:       ptr.To[int32](4),
+					AllowedImages:     []string{"ghcr.io/pytorch/*"},
+				},
+			}
+		})
+
+		It("Should apply the scheduling settings of the class", func() {
+			defaulter.Client = newClient(class)
+			obj.Spec.ClassName = "research"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.PodTemplate.PriorityClassName).To(Equal("research"))
+			Expect(obj.Spec.PodTemplate.SchedulerName).To(Equal("gang-scheduler"))
+			Expect(obj.Spec.PodTemplate.Tolerations).To(Equal(class.Spec.Tolerations))
+
+			By("Not adding the tolerations twice on update")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.PodTemplate.Tolerations).To(HaveLen(1))
+		})
+
+		It("Should reject meshes exceeding the limits of the class", func() {
+			validator.Client = newClient(class)
+			obj.Spec.ClassName = "research"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.Replicas = 8
+			obj.Spec.PodTemplate.SchedulerName = "default-scheduler"
+			obj.Spec.PodTemplate.Containers[0].Image = "docker.io/library/monarch:0.1.0"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.replicas"))
+			Expect(err.Error()).To(ContainSubstring("spec.podTemplate.schedulerName"))
+			Expect(err.Error()).To(ContainSubstring("spec.podTemplate.containers[0].image"))
+
+			By("Admitting updates that don't change the spec")
+			updated := obj.DeepCopy()
+			updated.Annotations = map[string]string{"team": "research"}
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().NotTo(HaveOccurred())
+
+			By("Admitting updates that only suspend or resume the mesh")
+			updated.Spec.Suspend = true
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().NotTo(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, updated, obj)).Error().NotTo(HaveOccurred())
+
+			By("Rejecting updates that change the rest of the spec")
+			updated.Spec.Replicas = 6
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().To(HaveOccurred())
+		})
+
+		It("Should reject a startup barrier image not allowed by the class", func() {
+			validator.Client = newClient(class)
+			obj.Spec.ClassName = "research"
+			obj.Spec.StartupBarrier = &monarchv1alpha1.MeshStartupBarrier{Image: "docker.io/library/busybox"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.startupBarrier.image"))
+
+			By("Admitting the default barrier image of the operator")
+			obj.Spec.StartupBarrier.Image = ""
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should leave the default class of the namespace to the reconciler", func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{monarchv1alpha1.DefaultClassAnnotation: "research"},
+			}}
+			defaulter.Client = newClient(ns, class)
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.ClassName).To(BeEmpty())
+			Expect(obj.Spec.PodTemplate.PriorityClassName).To(BeEmpty())
+		})
+
+		It("Should reject classes not allowed in the namespace", func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+				Annotations: map[string]string{
+					monarchv1alpha1.DefaultClassAnnotation:   "research",
+					monarchv1alpha1.AllowedClassesAnnotation: "batch",
+				},
+			}}
+			unrestricted := &monarchv1alpha1.MonarchMeshClass{ObjectMeta: metav1.ObjectMeta{Name: "unrestricted"}}
+			validator.Client = newClient(ns, class, unrestricted)
+			obj.Spec.ClassName = "unrestricted"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.className"))
+
+			By("Admitting the default class of the namespace")
+			obj.Spec.ClassName = "research"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should reject meshes of a missing class", func() {
+			obj.Spec.ClassName = "missing"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.className"))
+		})
+	})
+
+	Context("When the namespace has a MonarchMeshQuota", func() {
+		var quota *monarchv1alpha1.MonarchMeshQuota
+
+		BeforeEach(func() {
+			quota = &monarchv1alpha1.MonarchMeshQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+				Spec:       monarchv1alpha1.MonarchMeshQuotaSpec{Replicas: ptr.To[int32](4)},
+			}
+		})
+
+		It("Should reject meshes over quota", func() {
+			running := obj.DeepCopy()
+			running.Name = "running"
+			validator.Client = newClient(quota, running)
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.Replicas = 3
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("MonarchMeshQuota team (replicas: 5 > 4)"))
+
+			By("Admitting updates that don't increase the usage of the mesh")
+			updated := obj.DeepCopy()
+			updated.Spec.Replicas = 2
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().NotTo(HaveOccurred())
+
+			By("Rejecting resuming the mesh over quota")
+			obj.Spec.Suspend = true
+			updated = obj.DeepCopy()
+			updated.Spec.Suspend = false
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).Error().To(HaveOccurred())
+		})
+
+		It("Should admit meshes over a quota that queues them", func() {
+			quota.Spec.OverQuotaAction = monarchv1alpha1.OverQuotaQueue
+			validator.Client = newClient(quota)
+			obj.Spec.Replicas = 8
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+	})
+})